@@ -0,0 +1,189 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strconv"
+	"sync"
+)
+
+// aliasTable maps legacy names to their current names, and back.
+// It is safe for concurrent use.
+type aliasTable struct {
+	mu sync.RWMutex
+	// current maps a legacy name to the current one.
+	current map[string]string
+	// legacy maps a current name to the first legacy name registered for it.
+	legacy map[string]string
+}
+
+func newAliasTable() *aliasTable {
+	return &aliasTable{
+		current: map[string]string{},
+		legacy:  map[string]string{},
+	}
+}
+
+// register maps legacyName to currentName. If legacyName is already mapped to
+// another name, the existing mapping is kept and that name is returned with false.
+func (t *aliasTable) register(legacyName, currentName string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if existing, ok := t.current[legacyName]; ok && existing != currentName {
+		return existing, false
+	}
+	t.current[legacyName] = currentName
+	if _, ok := t.legacy[currentName]; !ok {
+		t.legacy[currentName] = legacyName
+	}
+	return currentName, true
+}
+
+// bind maps legacyName to currentName, both ways. Unlike register, it fails if
+// either name is already mapped to another one, returning the conflicting mapping.
+func (t *aliasTable) bind(legacyName, currentName string) (string, string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if existing, ok := t.current[legacyName]; ok && existing != currentName {
+		return legacyName, existing, false
+	}
+	if existing, ok := t.legacy[currentName]; ok && existing != legacyName {
+		return existing, currentName, false
+	}
+	t.current[legacyName] = currentName
+	t.legacy[currentName] = legacyName
+	return legacyName, currentName, true
+}
+
+// resolve returns the current name of legacyName.
+func (t *aliasTable) resolve(legacyName string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, ok := t.current[legacyName]
+	return name, ok
+}
+
+// reverse returns the first legacy name registered for currentName.
+func (t *aliasTable) reverse(currentName string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	name, ok := t.legacy[currentName]
+	return name, ok
+}
+
+var (
+	// rfcCodeAliases maps the previous RFC codes declared with PreviousRFCCodes
+	// to the current ones.
+	rfcCodeAliases = newAliasTable()
+	// mysqlCodeAliases maps the previous MySQL codes declared with PreviousMySQLCodes
+	// to the current ones in decimal. The keys are made by mysqlAliasKey.
+	mysqlCodeAliases = newAliasTable()
+)
+
+// mysqlAliasKey returns the key of mysqlCodeAliases for the previous MySQL code
+// of the errors with the given RFC code, or of any error for "".
+func mysqlAliasKey(codeText ErrCodeText, code ErrCode) string {
+	return string(codeText) + "#" + strconv.Itoa(int(code))
+}
+
+// PreviousRFCCodes returns a NormalizeOption declaring RFC codes the error was
+// known by in earlier releases. Equal and Is treat errors having one of them as
+// equal to the prototype, and UnmarshalJSON replaces them with the current code.
+// If a previous code is declared by several prototypes, the first one wins.
+func PreviousRFCCodes(codes ...string) NormalizeOption {
+	return func(e *Error) {
+		for _, code := range codes {
+			e.def.previousCodeTexts = append(e.def.previousCodeTexts, ErrCodeText(code))
+		}
+	}
+}
+
+// PreviousMySQLCodes is like PreviousRFCCodes, for MySQL error codes.
+// UnmarshalJSON only replaces them in errors with the RFC code of the
+// prototype, or without RFC code.
+func PreviousMySQLCodes(codes ...int) NormalizeOption {
+	return func(e *Error) {
+		for _, code := range codes {
+			e.def.previousCodes = append(e.def.previousCodes, ErrCode(code))
+		}
+	}
+}
+
+// PreviousRFCCodes returns the codes declared by PreviousRFCCodes.
+func (e *Error) PreviousRFCCodes() []RFCErrorCode {
+	e.checkReleased()
+	codes := make([]RFCErrorCode, len(e.definition().previousCodeTexts))
+	for i, code := range e.definition().previousCodeTexts {
+		codes[i] = RFCErrorCode(code)
+	}
+	return codes
+}
+
+// PreviousMySQLCodes returns the codes declared by PreviousMySQLCodes.
+func (e *Error) PreviousMySQLCodes() []ErrCode {
+	e.checkReleased()
+	return append([]ErrCode(nil), e.definition().previousCodes...)
+}
+
+// registerAliases makes the previous codes of a normalized error resolvable by UnmarshalJSON.
+func registerAliases(e *Error) {
+	for _, code := range e.def.previousCodeTexts {
+		rfcCodeAliases.register(string(code), string(e.codeText))
+	}
+	for _, code := range e.def.previousCodes {
+		current := strconv.Itoa(int(e.code))
+		mysqlCodeAliases.register(mysqlAliasKey(e.codeText, code), current)
+		mysqlCodeAliases.register(mysqlAliasKey("", code), current)
+	}
+}
+
+// knownAs reports whether id is the ID of e, or one of its previous codes.
+func (e *Error) knownAs(id ErrorID) bool {
+	if e.ID() == id {
+		return true
+	}
+	def := e.definition()
+	for _, code := range def.previousCodeTexts {
+		if ErrorID(code) == id {
+			return true
+		}
+	}
+	if e.codeText == "" {
+		for _, code := range def.previousCodes {
+			if ErrorID(strconv.Itoa(int(code))) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameKind reports whether e and other have the same ID, taking the previous
+// codes of both into account.
+func (e *Error) sameKind(other *Error) bool {
+	return e.knownAs(other.ID()) || other.knownAs(e.ID())
+}
+
+// resolveAliases replaces previous codes by the current ones. The MySQL code
+// is replaced if it is a previous code of the error with the current RFC code
+// of e, or of any error if e has no RFC code.
+func (e *Error) resolveAliases() {
+	if current, ok := rfcCodeAliases.resolve(string(e.codeText)); ok {
+		e.codeText = ErrCodeText(current)
+	}
+	if current, ok := mysqlCodeAliases.resolve(mysqlAliasKey(e.codeText, e.code)); ok {
+		code, _ := strconv.Atoi(current)
+		e.code = ErrCode(code)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousCodes(t *testing.T) {
+	errCurrent := Normalize("region %d is unavailable", RFCCodeText("test-alias:region:Unavailable"),
+		MySQLErrorCode(9105), PreviousRFCCodes("test-alias:kv:RegionUnavailable"), PreviousMySQLCodes(9005))
+	errLegacy := Normalize("region %d is unavailable", RFCCodeText("test-alias:kv:RegionUnavailable"), MySQLErrorCode(9005))
+	errOther := Normalize("other", RFCCodeText("test-alias:kv:Other"))
+
+	require.Equal(t, []RFCErrorCode{"test-alias:kv:RegionUnavailable"}, errCurrent.PreviousRFCCodes())
+	require.Equal(t, []ErrCode{9005}, errCurrent.PreviousMySQLCodes())
+
+	legacy := errLegacy.GenWithStackByArgs(1)
+	require.True(t, errCurrent.Equal(legacy))
+	require.True(t, errLegacy.Equal(errCurrent.GenWithStackByArgs(1)))
+	require.True(t, stderrors.Is(legacy, errCurrent))
+	require.True(t, stderrors.Is(errCurrent.FastGenByArgs(1), errLegacy))
+	require.True(t, ContainsCode(errOther.Wrap(legacy), errCurrent))
+	require.False(t, errCurrent.Equal(errOther.GenWithStackByArgs()))
+	require.False(t, HasCode(legacy, errCurrent.ID()))
+
+	// errors with a MySQL code only are matched by their previous MySQL codes.
+	errCodeOnly := Normalize("code only", MySQLErrorCode(9106), PreviousMySQLCodes(9006))
+	require.True(t, errCodeOnly.Equal(Normalize("code only", MySQLErrorCode(9006))))
+	require.False(t, errCodeOnly.Equal(Normalize("code only", MySQLErrorCode(9007))))
+}
+
+func TestUnmarshalPreviousCodes(t *testing.T) {
+	errCurrent := Normalize("table %s is locked", RFCCodeText("test-alias:table:Locked"),
+		MySQLErrorCode(9201), PreviousRFCCodes("test-alias:ddl:TableLocked"), PreviousMySQLCodes(9101))
+
+	var decoded Error
+	data := []byte(`{"code":9101,"message":"table t is locked","rfccode":"test-alias:ddl:TableLocked"}`)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, errCurrent.RFCCode(), decoded.RFCCode())
+	require.Equal(t, errCurrent.Code(), decoded.Code())
+	require.True(t, errCurrent.Equal(&decoded))
+
+	// codes without aliases are left unchanged.
+	data = []byte(`{"code":9102,"message":"other","rfccode":"test-alias:ddl:Other"}`)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, RFCErrorCode("test-alias:ddl:Other"), decoded.RFCCode())
+	require.Equal(t, ErrCode(9102), decoded.Code())
+
+	// previous MySQL codes are only replaced for the errors declaring them, or
+	// without RFC code.
+	data = []byte(`{"code":9101,"message":"other","rfccode":"test-alias:ddl:Other"}`)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, ErrCode(9101), decoded.Code())
+	data = []byte(`{"code":9101,"message":"table t is locked"}`)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, ErrCode(9201), decoded.Code())
+}
+
+func TestLegacyClass(t *testing.T) {
+	var decoded Error
+	require.NoError(t, json.Unmarshal([]byte(`{"class":8,"code":9007,"message":"write conflict"}`), &decoded))
+	require.Equal(t, RFCErrorCode("kv:9007"), decoded.RFCCode())
+
+	data, err := json.Marshal(Normalize("write conflict", RFCCodeText("kv:9007"), MySQLErrorCode(9007)))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"class":8,"code":9007,"message":"write conflict","rfccode":"kv:9007","template":"write conflict"}`, string(data))
+}
+
+func TestRegisterErrorClass(t *testing.T) {
+	require.NoError(t, RegisterErrorClass(1001, "test-class"))
+	require.NoError(t, RegisterErrorClass(1001, "test-class"))
+	require.Error(t, RegisterErrorClass(1001, "test-other"))
+	require.Error(t, RegisterErrorClass(1002, "test-class"))
+	require.Error(t, RegisterErrorClass(8, "test-other"))
+	require.Error(t, RegisterErrorClass(0, "test-other"))
+	require.Error(t, RegisterErrorClass(1003, "test:other"))
+
+	component, ok := ErrorClassComponent(1001)
+	require.True(t, ok)
+	require.Equal(t, "test-class", component)
+	class, ok := ComponentErrorClass("test-class")
+	require.True(t, ok)
+	require.Equal(t, 1001, class)
+	_, ok = ErrorClassComponent(1002)
+	require.False(t, ok)
+
+	data, err := json.Marshal(Normalize("class", RFCCodeText("test-class:Foo"), MySQLErrorCode(7)))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"class":1001,"code":7,"message":"class","rfccode":"test-class:Foo","template":"class"}`, string(data))
+
+	var decoded Error
+	require.NoError(t, json.Unmarshal([]byte(`{"class":1001,"code":7,"message":"class"}`), &decoded))
+	require.Equal(t, RFCErrorCode("test-class:7"), decoded.RFCCode())
+}
@@ -201,3 +201,152 @@ func BenchmarkByArgsHackedStrFreeze(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkStackInterning(b *testing.B) {
+	cases := []struct {
+		name string
+		gen  func(i int) error
+	}{
+		{"Errorf", func(i int) error { return Errorf("error %d", i) }},
+		{"AddStack", func(i int) error { return AddStack(stderrors.New("error")) }},
+	}
+	for _, c := range cases {
+		for _, interned := range []bool{false, true} {
+			c := c
+			interned := interned
+			b.Run(fmt.Sprintf("%s/interned-%t", c.name, interned), func(b *testing.B) {
+				if interned {
+					EnableStackInterning(0)
+					defer DisableStackInterning()
+				}
+				var err error
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					err = c.gen(i)
+				}
+				b.StopTimer()
+				GlobalE = err
+			})
+		}
+	}
+}
+
+func BenchmarkGetMsg(b *testing.B) {
+	cases := []struct {
+		name string
+		args []interface{}
+	}{
+		{"string-int", []interface{}{"t1", 42}},
+		{"fallback", []interface{}{"t1", int32(42)}},
+	}
+	errPrototype := Normalize("table %s has %d rows", RFCCodeText("Internal:Bench"))
+	for _, c := range cases {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			err := errPrototype.FastGenByArgs(c.args...).(*Error)
+			var msg string
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				msg = err.renderMsg()
+			}
+			GlobalE = New(msg)
+		})
+	}
+}
+
+func BenchmarkErrorText(b *testing.B) {
+	errPrototype := Normalize("table %s has %d rows", RFCCodeText("Internal:Bench"))
+	err := errPrototype.FastGenByArgs("t1", 42).(*Error)
+	wrapped := err.Wrap(stderrors.New("cause"))
+	cases := []struct {
+		name string
+		text func() string
+	}{
+		{"sprintf", func() string { return fmt.Sprintf("[%s]%s", err.RFCCode(), err.renderMsg()) }},
+		{"cached", err.Error},
+		{"cached-cause", wrapped.Error},
+	}
+	for _, c := range cases {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			var text string
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				text = c.text()
+			}
+			GlobalE = New(text)
+		})
+	}
+}
+
+// baselineError has the fields *Error had before its definition options were
+// added, to compare the memory used by FastGenByArgs with the one it used.
+type baselineError struct {
+	code          ErrCode
+	codeText      ErrCodeText
+	message       string
+	redactArgsPos []int
+	cause         error
+	args          []interface{}
+	file          string
+	line          int
+}
+
+func (e *baselineError) Error() string { return e.message }
+
+func (e *baselineError) fastGenByArgs(args ...interface{}) error {
+	RedactErrorArg(args, e.redactArgsPos)
+	err := *e
+	err.args = freezeHackedStringArgs(args)
+	return SuspendStack(&err)
+}
+
+func BenchmarkFastGen(b *testing.B) {
+	errPrototype := Normalize("table %s has %d rows", RFCCodeText("Internal:Bench"))
+	errNoArgs := Normalize("write conflict", RFCCodeText("Internal:BenchNoArgs"))
+	errBaseline := &baselineError{codeText: "Internal:Bench", message: "table %s has %d rows"}
+	cases := []struct {
+		name string
+		gen  func() error
+	}{
+		{"baseline", func() error { return errBaseline.fastGenByArgs("t1", 42) }},
+		{"no-args", func() error { return errNoArgs.FastGenByArgs() }},
+		{"args", func() error { return errPrototype.FastGenByArgs("t1", 42) }},
+		{"format", func() error { return errPrototype.FastGen("table %s is empty", "t1") }},
+		{"named", func() error { return errPrototype.FastGenByNamed(nil) }},
+		// more args than fastErrorArgs are allocated apart from the *Error.
+		{"many-args", func() error { return errPrototype.FastGenByArgs(1, 2, 3, 4, 5) }},
+	}
+	for _, c := range cases {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			var err error
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err = c.gen()
+			}
+			GlobalE = err
+		})
+	}
+}
+
+func BenchmarkPooled(b *testing.B) {
+	cases := []struct {
+		name string
+		opts []NormalizeOption
+	}{
+		{"fast-gen", nil},
+		{"pooled", []NormalizeOption{Pooled()}},
+	}
+	for _, c := range cases {
+		errPrototype := Normalize("write conflict, txnStartTS=%d, key=%s", append(c.opts, RFCCodeText("Internal:Bench"))...)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err := errPrototype.FastGenByArgs(42, "k")
+				GlobalE = err
+				Release(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalog loads the message catalogs of github.com/pingcap/errors from
+// the TOML files written by errdoc-gen, see errors.LoadMessageCatalog:
+//
+//	["tikv:kv:WriteConflict"]
+//	error = '''
+//	写冲突, txnStartTS=%d
+//	'''
+//
+// The other fields of the entries, like description, are ignored.
+package catalog
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pingcap/errors"
+)
+
+// entry is an entry of the TOML files written by errdoc-gen.
+type entry struct {
+	Error string `toml:"error"`
+}
+
+// Load loads the message templates of errors in the given locale from a TOML
+// document, see errors.LoadMessageCatalog.
+func Load(locale string, data []byte) error {
+	templates, err := parse(data)
+	if err != nil {
+		return errors.Annotatef(err, "invalid message catalog for locale %s", locale)
+	}
+	return errors.LoadMessageCatalog(locale, templates)
+}
+
+// LoadFile is like Load, reading the document from a file.
+func LoadFile(locale, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return Load(locale, data)
+}
+
+// LoadReference adds the default message templates of a TOML document, like
+// the errors.toml file written by errdoc-gen, to the ones the catalogs are
+// checked against, see errors.AddReferenceTemplates.
+func LoadReference(data []byte) error {
+	templates, err := parse(data)
+	if err != nil {
+		return errors.Annotate(err, "invalid reference message catalog")
+	}
+	errors.AddReferenceTemplates(templates)
+	return nil
+}
+
+// LoadReferenceFile is like LoadReference, reading the document from a file.
+func LoadReferenceFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return LoadReference(data)
+}
+
+// parse returns the message templates of a TOML document, by RFC code.
+func parse(data []byte) (map[string]string, error) {
+	var entries map[string]entry
+	if err := toml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	templates := make(map[string]string, len(entries))
+	for code, e := range entries {
+		templates[code] = strings.TrimSuffix(e.Error, "\n")
+	}
+	return templates, nil
+}
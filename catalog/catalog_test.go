@@ -0,0 +1,72 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	errConflict := errors.Normalize("Write conflict, txnStartTS=%d, key=%s", errors.RFCCodeText("test-catalog:kv:WriteConflict"))
+	errBusy := errors.Normalize("TiKV server is busy", errors.RFCCodeText("test-catalog:kv:ServerIsBusy"))
+
+	zh := `# AUTOGENERATED BY github.com/pingcap/errors/errdoc-gen
+
+["test-catalog:kv:WriteConflict"]
+error = '''
+写冲突, txnStartTS=%d, key=%s
+'''
+description = '''
+ignored
+'''
+
+["test-catalog:kv:ServerIsBusy"]
+error = '''
+TiKV 服务器繁忙
+'''
+`
+	path := filepath.Join(t.TempDir(), "errors_zh.toml")
+	require.NoError(t, os.WriteFile(path, []byte(zh), 0644))
+	require.NoError(t, LoadFile("zh", path))
+
+	require.Equal(t, "写冲突, txnStartTS=42, key=k", errors.LocalizedMsg(errConflict.GenWithStackByArgs(42, "k"), "zh"))
+	require.Equal(t, "TiKV 服务器繁忙", errors.LocalizedMsg(errBusy.FastGenByArgs(), "zh-CN"))
+
+	require.ErrorContains(t, Load("ja", []byte(`["test-catalog:kv:WriteConflict"]
+error = "書き込み競合 %d"
+`)), "test-catalog:kv:WriteConflict takes 1 args instead of 2")
+	require.Error(t, Load("ja", []byte("not toml [")))
+	require.Error(t, LoadFile("ja", filepath.Join(t.TempDir(), "missing.toml")))
+}
+
+func TestLoadReferenceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`["test-catalog:kv:NotCreated"]
+error = '''
+table %s.%s not found
+'''
+`), 0644))
+	require.NoError(t, LoadReferenceFile(path))
+	require.ErrorContains(t, Load("zh", []byte(`["test-catalog:kv:NotCreated"]
+error = "表 %s 不存在"
+`)), "test-catalog:kv:NotCreated takes 1 args instead of 2")
+
+	require.Error(t, LoadReference([]byte("not toml [")))
+	require.Error(t, LoadReferenceFile(filepath.Join(t.TempDir(), "missing.toml")))
+}
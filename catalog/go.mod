@@ -0,0 +1,21 @@
+module github.com/pingcap/errors/catalog
+
+go 1.18
+
+// catalog needs LoadMessageCatalog, first released in pingcap/errors v0.11.5:
+// that root release must be tagged before catalog is. The replace directive only
+// applies when working in this repository.
+replace github.com/pingcap/errors => ../
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/pingcap/errors v0.11.5
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,94 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+// DirectCause returns the error directly wrapped by err, or nil if it wraps none.
+// The Go 1.13 Unwrap method is preferred over Cause, so for an *Error it is the
+// error given to Wrap, while (*Error).Cause returns the cause of that error.
+func DirectCause(err error) error {
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		return x.Unwrap()
+	case interface{ Cause() error }:
+		return x.Cause()
+	}
+	return nil
+}
+
+// RootCause follows DirectCause until it reaches an error wrapping nothing and
+// returns that error. It returns err itself if err wraps nothing, nil for nil.
+//
+// Unlike Cause, it steps into errors only implementing Unwrap, like the ones of
+// fmt.Errorf with %w. The members of Join or ErrorGroup are not visited.
+func RootCause(err error) error {
+	for {
+		cause := DirectCause(err)
+		if cause == nil {
+			return err
+		}
+		err = cause
+	}
+}
+
+// Chain returns err and every error it wraps, following DirectCause from err to
+// RootCause(err). It returns nil for nil.
+func Chain(err error) []error {
+	var chain []error
+	for ; err != nil; err = DirectCause(err) {
+		chain = append(chain, err)
+	}
+	return chain
+}
+
+// HasCode reports whether any *Error in the graph of err has the given ID.
+// Every layer is looked at: the ones wrapped by *Error, the members of Join
+// and ErrorGroup included.
+func HasCode(err error, id ErrorID) bool {
+	return walkAll(err, func(err error) bool {
+		e, ok := err.(*Error)
+		return ok && e.ID() == id
+	})
+}
+
+// ContainsCode reports whether any *Error in the graph of err has the same ID
+// as prototype, in contrast to prototype.Equal(err) which only compares the root
+// cause unless the prototype is normalized with MatchAnyLayer. Like Equal, it
+// takes the previous codes into account, see PreviousRFCCodes.
+//
+// An error can hold several codes, e.g. ErrOuter.Wrap(ErrInner.GenWithStackByArgs()).
+// ContainsCode matches all of them. When a single code has to be picked, the
+// outermost one takes precedence: it is the first one printed by Error() and the
+// first one returned by Codes. Equal, ErrorEqual and Cause use the innermost one.
+func ContainsCode(err error, prototype *Error) bool {
+	if prototype == nil {
+		return false
+	}
+	return walkAll(err, func(err error) bool {
+		e, ok := err.(*Error)
+		return ok && prototype.sameKind(e)
+	})
+}
+
+// Codes returns the RFC codes of every *Error in the graph of err, the outermost
+// first, in the order ContainsCode looks at them.
+func Codes(err error) []RFCErrorCode {
+	var codes []RFCErrorCode
+	walkAll(err, func(err error) bool {
+		if e, ok := err.(*Error); ok {
+			codes = append(codes, e.RFCCode())
+		}
+		return false
+	})
+	return codes
+}
@@ -15,6 +15,7 @@ package errors
 
 import (
 	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -22,7 +23,7 @@ import (
 // class2RFCCode is used for compatible with old version of TiDB. When
 // marshal Error to json, old version of TiDB contain a 'class' field
 // which is represented for error class. In order to parse and convert
-// json to errors.Error, using this map to convert error class to RFC
+// json to errors.Error, legacyClasses is used to convert error class to RFC
 // error code text. here is reference:
 // https://github.com/pingcap/parser/blob/release-3.0/terror/terror.go#L58
 var class2RFCCode = map[int]string{
@@ -54,13 +55,51 @@ var class2RFCCode = map[int]string{
 	26: "plugin",
 	27: "util",
 }
-var rfcCode2class map[string]int
+
+// legacyClasses maps the legacy error classes, in decimal, to the components of
+// the RFC codes, see RegisterErrorClass.
+var legacyClasses = newAliasTable()
 
 func init() {
-	rfcCode2class = make(map[string]int)
-	for k, v := range class2RFCCode {
-		rfcCode2class[v] = k
+	for class, component := range class2RFCCode {
+		legacyClasses.bind(strconv.Itoa(class), component)
+	}
+}
+
+// RegisterErrorClass maps a legacy error class to the component of RFC codes.
+// MarshalJSON fills the deprecated 'class' field of errors of the component with
+// it, and UnmarshalJSON builds the RFC code {component}:{code} of payloads having
+// only a class. The classes of TiDB 3.0 are registered by default.
+//
+// It fails if the class or the component is already registered with another
+// mapping. It is safe for concurrent use.
+func RegisterErrorClass(class int, component string) error {
+	if class <= 0 {
+		return Errorf("invalid error class %d for component %q", class, component)
+	}
+	if component == "" || strings.Contains(component, ":") {
+		return Errorf("invalid component %q for error class %d", component, class)
+	}
+	if legacy, current, ok := legacyClasses.bind(strconv.Itoa(class), component); !ok {
+		return Errorf("error class %d for component %q conflicts with error class %s for component %q",
+			class, component, legacy, current)
+	}
+	return nil
+}
+
+// ErrorClassComponent returns the component registered for the legacy error class.
+func ErrorClassComponent(class int) (string, bool) {
+	return legacyClasses.resolve(strconv.Itoa(class))
+}
+
+// ComponentErrorClass returns the legacy error class registered for the component.
+func ComponentErrorClass(component string) (int, bool) {
+	name, ok := legacyClasses.reverse(component)
+	if !ok {
+		return 0, false
 	}
+	class, _ := strconv.Atoi(name)
+	return class, true
 }
 
 // MarshalJSON implements json.Marshaler interface.
@@ -68,14 +107,69 @@ func init() {
 // since we cannot access the registry when unmarshaling,
 // and the original global registry would be removed here.
 // This function is reserved for compatibility.
+//
+// Besides the rendered message, the payload holds the message template, the
+// args formatted with %v and the cause, so that the decoded error has the same
+// MessageTemplate and can generate new errors. The cause is encoded the same way
+// if it is an *Error. Otherwise the layers of its chain, see Chain, are encoded
+// by their own message down to the first *Error, so that annotations like the
+// ones of Annotate are kept, and stack traces are dropped. A layer whose own
+// message can not be told apart from the one of its cause ends the chain with
+// its full message.
 func (e *Error) MarshalJSON() ([]byte, error) {
-	ec := strings.Split(string(e.codeText), ":")[0]
-	return json.Marshal(&jsonError{
-		Class:   rfcCode2class[ec],
-		Code:    int(e.code),
-		Msg:     e.GetMsg(),
-		RFCCode: string(e.codeText),
-	})
+	e.checkReleased()
+	return json.Marshal(e.toJSON())
+}
+
+func (e *Error) toJSON() *jsonError {
+	class, _ := ComponentErrorClass(strings.Split(string(e.codeText), ":")[0])
+	j := &jsonError{
+		Class:    class,
+		Code:     int(e.code),
+		Msg:      e.GetMsg(),
+		RFCCode:  string(e.codeText),
+		Template: e.message,
+	}
+	for _, arg := range e.args {
+		j.Args = append(j.Args, fmt.Sprint(arg))
+	}
+	if e.cause != nil {
+		j.Cause = causeToJSON(e.cause)
+	}
+	return j
+}
+
+// causeToJSON encodes the layers of the chain of err, see MarshalJSON.
+func causeToJSON(err error) *jsonError {
+	if e, ok := err.(*Error); ok {
+		return e.toJSON()
+	}
+	cause := DirectCause(err)
+	if cause == nil {
+		return &jsonError{Msg: err.Error()}
+	}
+	msg, causeMsg := err.Error(), cause.Error()
+	if msg == causeMsg {
+		// a stack trace, like the ones of WithStack.
+		return causeToJSON(cause)
+	}
+	if !strings.HasSuffix(msg, ": "+causeMsg) {
+		return &jsonError{Msg: msg}
+	}
+	return &jsonError{Msg: strings.TrimSuffix(msg, ": "+causeMsg), Cause: causeToJSON(cause)}
+}
+
+// causeFromJSON decodes a cause encoded by causeToJSON.
+func causeFromJSON(j *jsonError) error {
+	if j.Code != 0 || j.RFCCode != "" || j.Class != 0 {
+		e := &Error{}
+		e.fromJSON(j)
+		return e
+	}
+	if j.Cause != nil {
+		return WithMessage(causeFromJSON(j.Cause), j.Msg)
+	}
+	return NewNoStackError(j.Msg)
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface.
@@ -83,16 +177,97 @@ func (e *Error) MarshalJSON() ([]byte, error) {
 // since we cannot access the registry in this context,
 // and the original global registry is removed.
 // This function is reserved for compatibility.
+// The previous codes declared with PreviousRFCCodes and PreviousMySQLCodes are
+// replaced by the current ones.
+//
+// The args of the decoded error are strings, but GetMsg returns the message as
+// rendered by the sender until new args are given by one of the Gen* methods.
+// The annotations of the cause are decoded by WithMessage, and a cause without
+// code by NewNoStackError. Payloads of older versions, without template, decode
+// to an error whose template is the rendered message.
+//
+// Decoding into a generated error detaches it from its prototype: it loses the
+// options of the prototype, like SQLState or RedactArgs, and is not pooled any
+// more. The instance shared by FastGenByArgs without args can not be decoded into.
 func (e *Error) UnmarshalJSON(data []byte) error {
+	e.checkReleased()
+	if e.isStacklessInstance() {
+		return Errorf("%s is shared by FastGenByArgs and can not be decoded into", e.ID())
+	}
 	tErr := &jsonError{}
 	if err := json.Unmarshal(data, &tErr); err != nil {
 		return Trace(err)
 	}
-	e.codeText = ErrCodeText(tErr.RFCCode)
-	if tErr.RFCCode == "" && tErr.Class > 0 {
-		e.codeText = ErrCodeText(class2RFCCode[tErr.Class] + ":" + strconv.Itoa(tErr.Code))
-	}
-	e.code = ErrCode(tErr.Code)
-	e.message = tErr.Msg
+	e.fromJSON(tErr)
 	return nil
 }
+
+func (e *Error) fromJSON(j *jsonError) {
+	r := RemoteError{
+		RFCCode:  RFCErrorCode(j.RFCCode),
+		Code:     ErrCode(j.Code),
+		Template: j.Template,
+		Msg:      j.Msg,
+		Args:     j.Args,
+	}
+	if j.RFCCode == "" && j.Class > 0 {
+		component, _ := ErrorClassComponent(j.Class)
+		r.RFCCode = RFCErrorCode(component + ":" + strconv.Itoa(j.Code))
+	}
+	if j.Cause != nil {
+		r.Cause = causeFromJSON(j.Cause)
+	}
+	e.fromRemote(&r)
+}
+
+// RemoteError describes an *Error sent by another process, for the codecs
+// other than MarshalJSON, like the one of the grpcerr package.
+type RemoteError struct {
+	RFCCode RFCErrorCode
+	Code    ErrCode
+	// Template is the message template of the error, see MessageTemplate, and
+	// Msg its message as rendered by the sender, see GetMsg.
+	Template string
+	Msg      string
+	// Args are the args of the error formatted by the sender.
+	Args []string
+	// Cause is the error wrapped by the error, if any.
+	Cause error
+}
+
+// FromRemote returns the *Error described by r, like UnmarshalJSON does: the
+// previous codes are replaced by the current ones, and GetMsg returns Msg until
+// new args are given by one of the Gen* methods. Without Template, the template
+// of the error is Msg.
+func FromRemote(r RemoteError) *Error {
+	e := &Error{}
+	e.fromRemote(&r)
+	return e
+}
+
+func (e *Error) fromRemote(r *RemoteError) {
+	// e may have been generated from a prototype, whose definition and pool no
+	// longer apply.
+	e.def = nil
+	e.pooled = nil
+	e.codeText = ErrCodeText(r.RFCCode)
+	e.code = r.Code
+	e.message = r.Msg
+	e.args = nil
+	e.rendered = false
+	e.text.Store(nil)
+	e.tmpl = nil
+	if r.Template != "" {
+		e.message = r.Template
+		e.rendered = true
+		e.tmpl = parseTemplate(r.Template)
+	}
+	for _, arg := range r.Args {
+		e.args = append(e.args, arg)
+	}
+	e.cause = r.Cause
+	e.resolveAliases()
+	if e.rendered {
+		e.setText(r.Msg)
+	}
+}
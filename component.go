@@ -0,0 +1,236 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Severity tells how serious an error is.
+type Severity int
+
+const (
+	SeverityUnspecified Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	default:
+		return "unspecified"
+	}
+}
+
+// WithSeverity returns a NormalizeOption to set the severity of an error.
+func WithSeverity(s Severity) NormalizeOption {
+	return func(e *Error) {
+		e.def.severity = s
+	}
+}
+
+// WithCategory returns a NormalizeOption to set the category of an error,
+// a free form name like "network" or "user-input" to classify errors by.
+func WithCategory(category string) NormalizeOption {
+	return func(e *Error) {
+		e.def.category = category
+	}
+}
+
+// Severity returns the severity set by WithSeverity.
+func (e *Error) Severity() Severity {
+	e.checkReleased()
+	return e.definition().severity
+}
+
+// Category returns the category set by WithCategory.
+func (e *Error) Category() string {
+	e.checkReleased()
+	return e.definition().category
+}
+
+// Component defines the errors of a component, like "tikv" or "pd". Errors are
+// defined through the classes of the component:
+//
+//	var (
+//	    tikv = errors.NewComponent("tikv", errors.WithCategory("storage"))
+//	    kv   = tikv.Class("kv")
+//
+//	    ErrWriteConflict = kv.Define(9007, "Write conflict, txnStartTS=%d", errors.RedactArgs([]int{0}))
+//	)
+//
+// Every defined error is registered to its Component, so that tools like
+// errdoc-gen can list them, see RegisteredErrors.
+type Component struct {
+	ns   Namespace
+	opts []NormalizeOption
+
+	mu     sync.Mutex
+	errors []*Error
+	// defined indexes errors by RFC code.
+	defined map[ErrCodeText]*Error
+}
+
+var (
+	componentsMu sync.Mutex
+	components   = map[string]*Component{}
+
+	mysqlCodesMu sync.RWMutex
+	// mysqlCodes indexes the RFC codes of the errors defined by components by
+	// their MySQL error code.
+	mysqlCodes = map[ErrCode][]ErrCodeText{}
+)
+
+// NewComponent returns the Component with the given name. The options are
+// applied to every error defined by the component before the ones of the class
+// and of the definition. Calling it again with the same name returns the same
+// Component, the options of the first call are kept.
+func NewComponent(name string, opts ...NormalizeOption) *Component {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	if c, ok := components[name]; ok {
+		return c
+	}
+	c := &Component{ns: NewNamespace(name), opts: opts}
+	components[name] = c
+	return c
+}
+
+// Name returns the name of the component, the first part of its RFC codes.
+func (c *Component) Name() string {
+	return c.ns.Component()
+}
+
+// Class returns a Class defining errors of the given error class in the component.
+// Its options are applied after the ones of the component.
+func (c *Component) Class(name string, opts ...NormalizeOption) *Class {
+	return &Class{
+		component: c,
+		name:      name,
+		opts:      opts,
+	}
+}
+
+// Errors returns the errors defined by the component, in definition order.
+func (c *Component) Errors() []*Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*Error(nil), c.errors...)
+}
+
+func (c *Component) register(e *Error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.defined[e.codeText]; ok {
+		panic(fmt.Sprintf("errors: %s is defined twice", e.codeText))
+	}
+	if c.defined == nil {
+		c.defined = map[ErrCodeText]*Error{}
+	}
+	c.defined[e.codeText] = e
+	c.errors = append(c.errors, e)
+	indexMySQLCode(e)
+}
+
+func indexMySQLCode(e *Error) {
+	mysqlCodesMu.Lock()
+	defer mysqlCodesMu.Unlock()
+	for _, codeText := range mysqlCodes[e.code] {
+		if codeText == e.codeText {
+			return
+		}
+	}
+	mysqlCodes[e.code] = append(mysqlCodes[e.code], e.codeText)
+}
+
+// lookupMySQLCode returns the RFC code of the error defined by a component with
+// the given MySQL error code. It returns false if there is none, or several.
+func lookupMySQLCode(code ErrCode) (ErrCodeText, bool) {
+	mysqlCodesMu.RLock()
+	defer mysqlCodesMu.RUnlock()
+	if codeTexts := mysqlCodes[code]; len(codeTexts) == 1 {
+		return codeTexts[0], true
+	}
+	return "", false
+}
+
+// Class defines the errors of an error class of a Component.
+type Class struct {
+	component *Component
+	name      string
+	opts      []NormalizeOption
+}
+
+// Name returns the name of the class, the second part of its RFC codes.
+func (c *Class) Name() string {
+	return c.name
+}
+
+// Component returns the component the class belongs to.
+func (c *Class) Component() *Component {
+	return c.component
+}
+
+// Define creates and registers a new *Error, with the RFC code
+// {Component}:{Class}:{code} and the MySQL error code code. The MySQL code can be
+// changed by the options with MySQLErrorCode, the RFC code can not.
+// It panics if the RFC code is already defined.
+func (c *Class) Define(code int, message string, opts ...NormalizeOption) *Error {
+	all := make([]NormalizeOption, 0, len(c.component.opts)+len(c.opts)+len(opts)+1)
+	all = append(all, MySQLErrorCode(code))
+	all = append(all, c.component.opts...)
+	all = append(all, c.opts...)
+	all = append(all, opts...)
+	e := c.component.ns.Normalize(c.name, strconv.Itoa(code), message, all...)
+	c.component.register(e)
+	return e
+}
+
+// Components returns all components created by NewComponent, sorted by name.
+func Components() []*Component {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	all := make([]*Component, 0, len(components))
+	for _, c := range components {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// RegisteredErrors returns the errors defined by all components, grouped by
+// component in the order of Components.
+func RegisteredErrors() []*Error {
+	var all []*Error
+	for _, c := range Components() {
+		all = append(all, c.Errors()...)
+	}
+	return all
+}
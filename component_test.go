@@ -0,0 +1,96 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// forgetComponent removes the component with the given name and its errors at
+// the end of the test, so that it can define its errors again when the test is
+// run again.
+func forgetComponent(t *testing.T, name string) {
+	t.Cleanup(func() {
+		componentsMu.Lock()
+		c := components[name]
+		delete(components, name)
+		componentsMu.Unlock()
+		if c == nil {
+			return
+		}
+		mysqlCodesMu.Lock()
+		defer mysqlCodesMu.Unlock()
+		for _, e := range c.Errors() {
+			codeTexts := mysqlCodes[e.code][:0]
+			for _, codeText := range mysqlCodes[e.code] {
+				if codeText != e.codeText {
+					codeTexts = append(codeTexts, codeText)
+				}
+			}
+			mysqlCodes[e.code] = codeTexts
+		}
+	})
+}
+
+func TestComponent(t *testing.T) {
+	forgetComponent(t, "test-tikv")
+	forgetComponent(t, "test-pd")
+	tikv := NewComponent("test-tikv", WithCategory("storage"), WithSeverity(SeverityError))
+	require.Same(t, tikv, NewComponent("test-tikv"))
+	require.Equal(t, "test-tikv", tikv.Name())
+
+	kv := tikv.Class("kv", WithSeverity(SeverityWarning))
+	require.Equal(t, "kv", kv.Name())
+	require.Same(t, tikv, kv.Component())
+
+	errConflict := kv.Define(9007, "Write conflict on key %s", RedactArgs([]int{0}))
+	require.Equal(t, RFCErrorCode("test-tikv:kv:9007"), errConflict.RFCCode())
+	require.Equal(t, ErrCode(9007), errConflict.Code())
+	require.Equal(t, "storage", errConflict.Category())
+	require.Equal(t, SeverityWarning, errConflict.Severity())
+	require.Equal(t, "warning", errConflict.Severity().String())
+
+	RedactLogEnabled.Store(RedactLogEnable)
+	defer RedactLogEnabled.Store(RedactLogDisable)
+	err := errConflict.GenWithStackByArgs("secret")
+	require.Equal(t, "[test-tikv:kv:9007]Write conflict on key ?", err.Error())
+	require.True(t, errConflict.Equal(err))
+	require.Equal(t, SeverityWarning, Cause(err).(*Error).Severity())
+
+	errRegion := tikv.Class("region").Define(9005, "Region is unavailable",
+		MySQLErrorCode(1105), WithSeverity(SeverityFatal), RFCCodeText("ignored:code:1"))
+	require.Equal(t, RFCErrorCode("test-tikv:region:9005"), errRegion.RFCCode())
+	require.Equal(t, ErrCode(1105), errRegion.Code())
+	require.Equal(t, SeverityFatal, errRegion.Severity())
+
+	require.Equal(t, []*Error{errConflict, errRegion}, tikv.Errors())
+	require.PanicsWithValue(t, "errors: test-tikv:kv:9007 is defined twice", func() {
+		kv.Define(9007, "Write conflict again")
+	})
+	require.Equal(t, []*Error{errConflict, errRegion}, tikv.Errors())
+
+	pd := NewComponent("test-pd")
+	errNoLeader := pd.Class("client").Define(1, "no leader")
+	var names []string
+	for _, c := range Components() {
+		names = append(names, c.Name())
+	}
+	require.Subset(t, names, []string{"test-pd", "test-tikv"})
+	require.Subset(t, RegisteredErrors(), []*Error{errConflict, errRegion, errNoLeader})
+
+	require.Equal(t, SeverityUnspecified, Normalize("x").Severity())
+	require.Equal(t, "unspecified", Severity(42).String())
+}
@@ -106,7 +106,7 @@ import (
 	"github.com/BurntSushi/toml"
 	"github.com/pingcap/errors"
 {{- range $decl := .}}
-	{{$decl.PackageName}} "{{- $decl.ImportPath}}"
+	{{if $decl.ErrNames}}{{$decl.PackageName}}{{else}}_{{end}} "{{- $decl.ImportPath}}"
 {{- end}}
 )
 
@@ -135,6 +135,18 @@ func main() {
 	allErrors = append(allErrors, {{$decl.PackageName}}.{{- $err}})
 		{{- end}}
 	{{- end}}
+	{{- if usesComponents .}}
+	// Errors defined through errors.NewComponent need no naming convention.
+	listed := map[error]bool{}
+	for _, e := range allErrors {
+		listed[e] = true
+	}
+	for _, e := range errors.RegisteredErrors() {
+		if !listed[e] {
+			allErrors = append(allErrors, e)
+		}
+	}
+	{{- end}}
 
 	var dedup = map[string]spec{}
 	for _, e := range allErrors {
@@ -199,7 +211,9 @@ func main() {
 		"Workaround  string `toml:\"workaround\"`\n" +
 		"}"
 
-	t, err := template.New("_errdoc-template").Parse(tmpl)
+	t, err := template.New("_errdoc-template").Funcs(template.FuncMap{
+		"usesComponents": usesComponents,
+	}).Parse(tmpl)
 	if err != nil {
 		fatal("Parse template failed: %+v", err)
 	}
@@ -234,6 +248,20 @@ type errDecl struct {
 	ImportPath  string
 	PackageName string
 	ErrNames    []string
+	// UsesComponent is set if the package defines errors through a component.
+	UsesComponent bool
+}
+
+// usesComponents reports whether any package defines errors through a component.
+// errors.RegisteredErrors is only called then, so that the targets pinning a
+// version of pingcap/errors without it still build.
+func usesComponents(decls []*errDecl) bool {
+	for _, decl := range decls {
+		if decl.UsesComponent {
+			return true
+		}
+	}
+	return false
 }
 
 func errdoc(source, module string) ([]*errDecl, error) {
@@ -270,7 +298,8 @@ func errdoc(source, module string) ([]*errDecl, error) {
 			return nil
 		}
 		errNames := export(file)
-		if len(errNames) < 1 {
+		component := usesComponent(file)
+		if len(errNames) < 1 && !component {
 			return nil
 		}
 		dirPath := filepath.Dir(path)
@@ -281,11 +310,13 @@ func errdoc(source, module string) ([]*errDecl, error) {
 		packageName := strings.ReplaceAll(subPath, "/", "_")
 		if decl, found := dedup[packageName]; found {
 			decl.ErrNames = append(decl.ErrNames, errNames...)
+			decl.UsesComponent = decl.UsesComponent || component
 		} else {
 			decl := &errDecl{
-				ImportPath:  filepath.Join(module, subPath),
-				PackageName: packageName,
-				ErrNames:    errNames,
+				ImportPath:    filepath.Join(module, subPath),
+				PackageName:   packageName,
+				ErrNames:      errNames,
+				UsesComponent: component,
 			}
 			dedup[packageName] = decl
 		}
@@ -348,3 +379,25 @@ func export(f *ast.File) []string {
 	}
 	return errNames
 }
+
+// usesComponent reports whether the file calls NewComponent or Define, possibly
+// on a component created in another package. The errors defined by components
+// are registered and listed by errors.RegisteredErrors once the package is
+// imported, whatever their names are.
+func usesComponent(f *ast.File) bool {
+	found := false
+	ast.Inspect(f, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && (sel.Sel.Name == "NewComponent" || sel.Sel.Name == "Define") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
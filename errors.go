@@ -52,6 +52,18 @@
 // of stable public API.
 // errors.Unwrap is also available: this will retrieve the next error in the chain.
 //
+// errors.DirectCause, errors.RootCause and errors.Chain walk the chain with
+// precise semantics and are used by Equal, ErrorEqual and GetErrStackMsg.
+// Migrating from the causer based functions:
+//
+//   - (*Error).Cause returns the cause of the error given to Wrap, skipping one
+//     layer, and is kept as is for compatibility. Use errors.DirectCause for the
+//     error given to Wrap.
+//   - errors.Unwrap(err) calls Cause, so it has the same skip for *Error.
+//     errors.DirectCause prefers the Go 1.13 Unwrap method instead.
+//   - errors.Cause stops at errors that only implement Unwrap, like the ones
+//     returned by fmt.Errorf with %w. errors.RootCause steps into them.
+//
 // # Formatted printing of errors
 //
 // All error values returned from this package implement fmt.Formatter and can
@@ -60,8 +72,16 @@
 //	%s    print the error. If the error has a Cause it will be
 //	      printed recursively
 //	%v    see %s
+//	%q    the error message as a double-quoted string
+//	%x    the error message in base 16, %X in upper case
 //	%+v   extended format. Each Frame of the error's StackTrace will
 //	      be printed in detail.
+//	%-v   the message of every layer in the chain, one per line,
+//	      starting with the outermost one.
+//	%#+v  the whole error graph drawn as a tree, see errors.Tree.
+//
+// Width, precision and the other flags work for %s, %v, %q, %x and %X as they
+// do for strings.
 //
 // # Retrieving the stack trace of an error or wrapper
 //
@@ -81,7 +101,13 @@
 //	        }
 //	}
 //
-// See the documentation for Frame.Format for more details.
+// See the documentation for Frame.Format for more details. For programmatic use,
+// StackTrace.Frames returns each frame as a plain FrameInfo, which is also what
+// a StackTrace is marshaled to as JSON.
+//
+// After EnableStackMetadata is called, the time and goroutine an error was created
+// at are recorded together with its stack trace, they can be retrieved with
+// errors.GetStackMetadata.
 //
 // errors.Find can be used to search for an error in the error chain.
 package errors
@@ -89,6 +115,7 @@ package errors
 import (
 	"fmt"
 	"io"
+	"time"
 )
 
 // represent an error carries with message
@@ -103,6 +130,7 @@ func New(message string) error {
 	return &fundamental{
 		msg:   message,
 		stack: callers(),
+		meta:  captureStackMeta(),
 	}
 }
 
@@ -113,6 +141,7 @@ func Errorf(format string, args ...interface{}) error {
 	return &fundamental{
 		msg:   fmt.Sprintf(format, args...),
 		stack: callers(),
+		meta:  captureStackMeta(),
 	}
 }
 
@@ -129,8 +158,8 @@ func HasStack(err error) bool {
 		return errWithStack.HasStack()
 	}
 	// Error.FastGenXXX or call SuspendStack directly will make an empty stack trace,
-	// which should be considered as no stack trace, to allow upper layer code to
-	// add stack trace with Trace.
+	// or return an *Error without stack trace, which should be considered as no
+	// stack trace, to allow upper layer code to add stack trace with Trace.
 	stackTracer := GetStackTracer(err)
 	return stackTracer != nil && !stackTracer.Empty()
 }
@@ -139,28 +168,26 @@ func HasStack(err error) bool {
 type fundamental struct {
 	msg string
 	*stack
+	meta *stackMeta
 }
 
 var _ messenger = (*fundamental)(nil)
+var _ StackMetadata = (*fundamental)(nil)
 
 func (f *fundamental) Error() string { return f.msg }
 
 func (f *fundamental) GetSelfMsg() string { return f.msg }
 
-func (f *fundamental) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			io.WriteString(s, f.msg)
-			f.stack.Format(s, verb)
-			return
-		}
-		fallthrough
-	case 's':
-		io.WriteString(s, f.msg)
-	case 'q':
-		fmt.Fprintf(s, "%q", f.msg)
-	}
+func (f *fundamental) CreatedAt() time.Time { return f.meta.CreatedAt() }
+
+func (f *fundamental) GoroutineID() int64 { return f.meta.GoroutineID() }
+
+func (f *fundamental) Format(s fmt.State, verb rune) { formatError(s, verb, f) }
+
+func (f *fundamental) formatExtended(s fmt.State) {
+	writeMessage(s, f.msg)
+	f.meta.Format(s, 'v')
+	f.stack.Format(s, 'v')
 }
 
 // WithStack annotates err with a stack trace at the point WithStack was called.
@@ -176,6 +203,7 @@ func WithStack(err error) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
@@ -189,15 +217,18 @@ func AddStack(err error) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
 type withStack struct {
 	error
 	*stack
+	meta *stackMeta
 }
 
 var _ messenger = (*withStack)(nil)
+var _ StackMetadata = (*withStack)(nil)
 
 func (w *withStack) Cause() error { return w.error }
 
@@ -210,20 +241,16 @@ func (w *withStack) GetSelfMsg() string {
 // Unwrap provides compatibility for Go 1.13 error chains.
 func (w *withStack) Unwrap() error { return w.error }
 
-func (w *withStack) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v", w.Cause())
-			w.stack.Format(s, verb)
-			return
-		}
-		fallthrough
-	case 's':
-		io.WriteString(s, w.Error())
-	case 'q':
-		fmt.Fprintf(s, "%q", w.Error())
-	}
+func (w *withStack) CreatedAt() time.Time { return w.meta.CreatedAt() }
+
+func (w *withStack) GoroutineID() int64 { return w.meta.GoroutineID() }
+
+func (w *withStack) Format(s fmt.State, verb rune) { formatError(s, verb, w) }
+
+func (w *withStack) formatExtended(s fmt.State) {
+	writeExtended(s, w.Cause())
+	w.meta.Format(s, 'v')
+	w.stack.Format(s, 'v')
 }
 
 // Wrap returns an error annotating err with a stack trace
@@ -245,6 +272,7 @@ func Wrap(err error, message string) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
@@ -267,6 +295,7 @@ func Wrapf(err error, format string, args ...interface{}) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
@@ -300,20 +329,12 @@ func (w *withMessage) GetSelfMsg() string { return w.msg }
 func (w *withMessage) Unwrap() error  { return w.cause }
 func (w *withMessage) HasStack() bool { return w.causeHasStack }
 
-func (w *withMessage) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			fmt.Fprintf(s, "%+v\n", w.Cause())
-			io.WriteString(s, w.msg)
-			return
-		}
-		fallthrough
-	case 's':
-		io.WriteString(s, w.Error())
-	case 'q':
-		fmt.Fprintf(s, "%q", w.Error())
-	}
+func (w *withMessage) Format(s fmt.State, verb rune) { formatError(s, verb, w) }
+
+func (w *withMessage) formatExtended(s fmt.State) {
+	writeExtended(s, w.Cause())
+	io.WriteString(s, "\n")
+	writeMessage(s, w.msg)
 }
 
 // Cause returns the underlying cause of the error, if possible.
@@ -380,7 +401,7 @@ func GetErrStackMsg(err error) string {
 	m, ok := err.(messenger)
 	if ok {
 		msg := m.GetSelfMsg()
-		causeMsg := GetErrStackMsg(Unwrap(err))
+		causeMsg := GetErrStackMsg(DirectCause(err))
 		if msg == "" {
 			msg = causeMsg
 		} else if causeMsg != "" {
@@ -522,3 +522,126 @@ func TestGetErrStackMsg(t *testing.T) {
 	fastGen = namedErr.Wrap(urlErr).FastGen("fast gen")
 	require.Equal(t, `fast gen: GET "/url": internal golang err`, GetErrStackMsg(fastGen))
 }
+
+func TestHasCode(t *testing.T) {
+	e1 := Normalize("e1", RFCCodeText("e1"))
+	e2 := Normalize("e2", RFCCodeText("e2"))
+	e3 := Normalize("e3", RFCCodeText("e3"))
+	err := Annotate(e2.Wrap(e1.Wrap(fooError(100))), "annotated")
+
+	require.True(t, HasCode(err, "e1"))
+	require.True(t, HasCode(err, "e2"))
+	require.False(t, HasCode(err, "e3"))
+	require.False(t, HasCode(nil, "e1"))
+	require.True(t, HasCode(Join(io.EOF, e3.FastGenByArgs()), "e3"))
+	require.True(t, HasCode(&errWalkTest{sub: []error{e3}}, "e3"))
+}
+
+func TestContainsCode(t *testing.T) {
+	inner := Normalize("inner", RFCCodeText("Internal:Inner"))
+	outer := Normalize("outer", RFCCodeText("Internal:Outer"))
+	other := Normalize("other", RFCCodeText("Internal:Other"))
+	err := Annotate(outer.Wrap(inner.GenWithStackByArgs()), "annotated")
+
+	require.True(t, ContainsCode(err, inner))
+	require.True(t, ContainsCode(err, outer))
+	require.False(t, ContainsCode(err, other))
+	require.False(t, ContainsCode(err, nil))
+	require.Equal(t, []RFCErrorCode{"Internal:Outer", "Internal:Inner"}, Codes(err))
+	require.Nil(t, Codes(io.EOF))
+
+	// by default Equal only looks at the root cause.
+	wrapped := outer.Wrap(inner.Wrap(io.EOF))
+	require.False(t, inner.Equal(wrapped))
+	require.False(t, outer.Equal(wrapped))
+
+	anyInner := Normalize("inner", RFCCodeText("Internal:Inner"), MatchAnyLayer())
+	anyOuter := Normalize("outer", RFCCodeText("Internal:Outer"), MatchAnyLayer())
+	require.True(t, anyInner.Equal(wrapped))
+	require.True(t, anyOuter.Equal(wrapped))
+	require.True(t, anyInner.GenWithStackByArgs().(*withStack).error.(*Error).Equal(wrapped))
+	require.False(t, Normalize("other", RFCCodeText("Internal:Other"), MatchAnyLayer()).Equal(wrapped))
+	// the root cause is still compared as before.
+	require.True(t, anyInner.Equal(inner.FastGenByArgs()))
+}
+
+func TestCauseChain(t *testing.T) {
+	require.Nil(t, DirectCause(nil))
+	require.Nil(t, RootCause(nil))
+	require.Nil(t, Chain(nil))
+	require.Nil(t, DirectCause(io.EOF))
+	require.Equal(t, io.EOF, RootCause(io.EOF))
+	require.Equal(t, []error{io.EOF}, Chain(io.EOF))
+
+	e1 := Normalize("e1", RFCCodeText("e1"))
+	e2 := Normalize("e2", RFCCodeText("e2"))
+	middle := Annotate(fooError(100), "middle")
+	e1m := e1.Wrap(middle)
+	e21m := e2.Wrap(e1m)
+
+	// (*Error).Cause skips one layer, DirectCause does not.
+	require.Equal(t, error(e1m), e21m.Unwrap())
+	require.Equal(t, middle.(*withStack).error, e21m.Cause())
+	require.Equal(t, error(e1m), DirectCause(e21m))
+	require.Equal(t, middle.(*withStack).error, Unwrap(e21m))
+
+	require.Equal(t, []error{e21m, e1m, middle, middle.(*withStack).error, fooError(100)}, Chain(e21m))
+	require.Equal(t, fooError(100), RootCause(e21m))
+	require.Equal(t, Cause(e21m), RootCause(e21m))
+
+	// RootCause steps into errors only implementing Unwrap, Cause does not.
+	wrapped := fmt.Errorf("wrapped: %w", e2.FastGenByArgs())
+	require.Equal(t, wrapped, Cause(wrapped))
+	root, ok := RootCause(wrapped).(*Error)
+	require.True(t, ok)
+	require.Equal(t, ErrorID("e2"), root.ID())
+}
+
+func TestCauseChainConsumers(t *testing.T) {
+	e1 := Normalize("e1", RFCCodeText("e1"))
+	e2 := Normalize("e2", RFCCodeText("e2"))
+
+	// GetErrStackMsg no longer skips the layer right below an *Error.
+	err := e2.Wrap(e1.Wrap(Annotate(New("root"), "middle")))
+	require.Equal(t, "e2: e1: middle: root", GetErrStackMsg(err))
+
+	// Equal and ErrorEqual see through fmt.Errorf("%w").
+	wrapped := fmt.Errorf("wrapped: %w", e1.GenWithStackByArgs())
+	require.True(t, e1.Equal(wrapped))
+	require.False(t, e2.Equal(wrapped))
+	require.True(t, ErrorEqual(wrapped, e1.FastGenByArgs()))
+	require.True(t, ErrorEqual(fmt.Errorf("wrapped: %w", io.EOF), Trace(io.EOF)))
+
+	// both still compare the root cause only.
+	require.False(t, e1.Equal(e1.Wrap(io.EOF)))
+	require.True(t, ErrorEqual(e1.Wrap(io.EOF), e2.Wrap(io.EOF)))
+}
+
+func TestFastGenStackless(t *testing.T) {
+	errTest := Normalize("table %s has %d rows", RFCCodeText("Internal:FastGen"))
+	err := errTest.FastGenByArgs("t1", 42)
+	require.IsType(t, &Error{}, err)
+	require.False(t, HasStack(err))
+	require.True(t, HasStack(Trace(err)))
+	require.Equal(t, "[Internal:FastGen]table t1 has 42 rows", err.Error())
+	require.True(t, errTest.Equal(err))
+
+	// more args than held along with the error.
+	err = errTest.FastGenByArgs(1, 2, 3, 4, 5)
+	require.Equal(t, []interface{}{1, 2, 3, 4, 5}, err.(*Error).Args())
+
+	// the same instance is returned without args.
+	noArgs := Normalize("write conflict", RFCCodeText("Internal:FastGenNoArgs"))
+	require.Same(t, noArgs.FastGenByArgs(), noArgs.FastGenByArgs())
+	require.NotSame(t, noArgs, noArgs.FastGenByArgs())
+	require.Nil(t, noArgs.FastGenByArgs().(*Error).Args())
+	require.False(t, HasStack(noArgs.FastGenByArgs()))
+
+	// the stack of a cause is suspended.
+	wrapped := noArgs.Wrap(New("cause"))
+	require.False(t, HasStack(wrapped.FastGenByArgs()))
+	require.False(t, HasStack(wrapped.FastGenByArgs(1)))
+
+	require.Zero(t, testing.AllocsPerRun(100, func() { _ = noArgs.FastGenByArgs() }))
+	require.Equal(t, float64(1), testing.AllocsPerRun(100, func() { _ = errTest.FastGenByArgs("t1", 42) }))
+}
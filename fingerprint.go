@@ -0,0 +1,87 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+)
+
+// DefaultFingerprintFrames is the number of stack frames used by Fingerprint.
+const DefaultFingerprintFrames = 5
+
+// Fingerprint returns a hash identifying the kind of failure err is, so that
+// occurrences of the same failure can be grouped together. It is FingerprintN
+// with DefaultFingerprintFrames.
+func Fingerprint(err error) string {
+	return FingerprintN(err, DefaultFingerprintFrames)
+}
+
+// FingerprintN returns a hash of:
+//   - the RFC code and message template of every *Error in the chain, so the
+//     arguments the messages are rendered with do not matter,
+//   - the type of the root cause of every branch, if it is not an *Error,
+//   - the function and line of the top n frames of the innermost stack trace,
+//     runtime frames excluded.
+//
+// Messages of other errors are left out since they are usually rendered with
+// arguments. The result is stable across runs and across binaries built from
+// the same source. The empty string is returned for a nil error.
+func FingerprintN(err error, n int) string {
+	if err == nil {
+		return ""
+	}
+	h := sha1.New()
+	var st StackTrace
+	walkAll(err, func(err error) bool {
+		switch x := err.(type) {
+		case *Error:
+			writeFingerprintField(h, "code", string(x.RFCCode()))
+			writeFingerprintField(h, "template", x.MessageTemplate())
+		default:
+			if DirectCause(err) == nil {
+				writeFingerprintField(h, "type", fmt.Sprintf("%T", err))
+			}
+		}
+		if tracer, ok := err.(StackTracer); ok && !tracer.Empty() {
+			st = tracer.StackTrace()
+		}
+		return false
+	})
+	for _, f := range st {
+		if n <= 0 {
+			break
+		}
+		info := f.Info()
+		if info.Package == "runtime" {
+			continue
+		}
+		writeFingerprintField(h, "frame", info.Func+":"+strconv.Itoa(info.Line))
+		n--
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFingerprintField writes a length prefixed field, so that the boundaries
+// between fields can not be shifted to produce the same hash.
+func writeFingerprintField(h hash.Hash, name, value string) {
+	io.WriteString(h, name)
+	io.WriteString(h, strconv.Itoa(len(value)))
+	io.WriteString(h, ":")
+	io.WriteString(h, value)
+}
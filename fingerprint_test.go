@@ -0,0 +1,52 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	require.Equal(t, "", Fingerprint(nil))
+
+	errRegion := Normalize("Region %d is unavailable", RFCCodeText("tikv:region:Unavailable"))
+	errStore := Normalize("Store %d is unavailable", RFCCodeText("tikv:store:Unavailable"))
+
+	gen := func(proto *Error, id int) error {
+		return Annotate(proto.GenWithStackByArgs(id), "retry failed")
+	}
+	// the arguments and the messages rendered with them do not matter.
+	require.Equal(t, Fingerprint(gen(errRegion, 1)), Fingerprint(gen(errRegion, 2)))
+	require.Len(t, Fingerprint(gen(errRegion, 1)), 40)
+	// the codes and templates do.
+	require.NotEqual(t, Fingerprint(gen(errRegion, 1)), Fingerprint(gen(errStore, 1)))
+
+	// so do the stack frames.
+	errorf := func(i int) error { return Errorf("error %d", i) }
+	require.Equal(t, Fingerprint(errorf(1)), Fingerprint(errorf(2)))
+	require.NotEqual(t, Fingerprint(Errorf("error %d", 1)), Fingerprint(errorf(1)))
+	require.NotEqual(t, FingerprintN(Errorf("error"), 1), FingerprintN(Errorf("error"), 0))
+	require.Equal(t, FingerprintN(io.EOF, 0), FingerprintN(io.EOF, 3))
+
+	// every *Error layer counts, including the ones (*Error).Cause skips.
+	wrapped := errStore.Wrap(errRegion.Wrap(io.EOF))
+	require.NotEqual(t, Fingerprint(wrapped), Fingerprint(errStore.Wrap(io.EOF)))
+
+	// the root causes of all branches of a Join count.
+	require.NotEqual(t, Fingerprint(Join(io.EOF, errRegion)), Fingerprint(Join(io.EOF, errStore)))
+	require.NotEqual(t, Fingerprint(Join(io.EOF)), Fingerprint(Join(nilError{})))
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// extendedFormatter is implemented by the error types of this package to write
+// their %+v form: the %+v form of what they wrap, their own message and their
+// own stack trace, if they have one.
+type extendedFormatter interface {
+	formatExtended(s fmt.State)
+}
+
+// formatError is the fmt.Formatter shared by all error types of this package.
+//
+//	%s %v %q %x %X  err.Error() with width, precision and flags applied as fmt
+//	                does for strings.
+//	%+v             the extended format, see extendedFormatter.
+//	%-v             the message of every layer of the chain, one per line,
+//	                the outermost first.
+//	%#+v            the tree drawn by Tree.
+//
+// Other verbs are reported like fmt does for bad verbs, e.g. %!d(*errors.fundamental=msg).
+func formatError(s fmt.State, verb rune, err error) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+') && s.Flag('#'):
+			io.WriteString(s, Tree(err))
+			return
+		case s.Flag('+'):
+			if ef, ok := err.(extendedFormatter); ok {
+				ef.formatExtended(s)
+				return
+			}
+		case s.Flag('-'):
+			io.WriteString(s, strings.Join(chainMessages(err, nil), "\n"))
+			return
+		}
+		fmt.Fprintf(s, fmt.FormatString(s, 's'), err.Error())
+	case 's', 'q', 'x', 'X':
+		fmt.Fprintf(s, fmt.FormatString(s, verb), err.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%T=%s)", verb, err, err.Error())
+	}
+}
+
+// continuationIndent prefixes the lines of a message after the first one in the
+// extended format, so that they can not be taken for the message of another
+// layer or for a stack frame. It also prefixes the lines of the members of a Join
+// after the first one, which is prefixed by joinMemberPrefix.
+const (
+	continuationIndent = "  "
+	joinMemberPrefix   = "- "
+)
+
+// writeMessage writes msg for the extended format, see continuationIndent.
+func writeMessage(w io.Writer, msg string) {
+	io.WriteString(w, strings.ReplaceAll(msg, "\n", "\n"+continuationIndent))
+}
+
+// writeExtended writes the %+v form of err: the extended format of the errors of
+// this package, what other errors implementing fmt.Formatter print, or else the
+// message of err written by writeMessage.
+func writeExtended(w io.Writer, err error) {
+	if _, ok := err.(fmt.Formatter); ok {
+		fmt.Fprintf(w, "%+v", err)
+		return
+	}
+	writeMessage(w, err.Error())
+}
+
+// chainMessages appends the message of every layer of err to msgs, the outermost
+// first. The members of a Join or ErrorGroup are appended one after another.
+func chainMessages(err error, msgs []string) []string {
+	for err != nil {
+		switch x := err.(type) {
+		case *Error:
+			msgs = append(msgs, "["+string(x.RFCCode())+"]"+x.GetMsg())
+		case messenger:
+			if msg := x.GetSelfMsg(); msg != "" {
+				msgs = append(msgs, msg)
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				msgs = chainMessages(err, msgs)
+			}
+			return msgs
+		case ErrorGroup:
+			for _, err := range x.Errors() {
+				msgs = chainMessages(err, msgs)
+			}
+			return msgs
+		default:
+			// the message of a foreign error already contains the ones it wraps.
+			return append(msgs, err.Error())
+		}
+		err = DirectCause(err)
+	}
+	return msgs
+}
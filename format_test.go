@@ -378,6 +378,9 @@ func testFormatRegexp(t *testing.T, n int, arg interface{}, format, want string)
 			t.Errorf("test %d: line %d: fmt.Sprintf(%q, err):\n got: %q\nwant: %q", n+1, i+1, format, got, want)
 		}
 	}
+	if _, ok := arg.(error); ok && format == "%+v" {
+		testParsedRegexp(t, n, got, want)
+	}
 }
 
 var stackLineR = regexp.MustCompile(`\.`)
@@ -482,6 +485,9 @@ func testFormatCompleteCompare(t *testing.T, n int, arg interface{}, format stri
 			}
 		}
 	}
+	if format == "%+v" {
+		testParsedBlocks(t, n, gotStr, want, detectStackBoundaries)
+	}
 }
 
 type wrapper struct {
@@ -531,3 +537,104 @@ func testGenericRecursive(t *testing.T, beforeErr error, beforeWant []string, li
 		}
 	}
 }
+
+func TestFormatVerbMatrix(t *testing.T) {
+	errTest := Normalize("named error: %s", RFCCodeText("Internal:Test"))
+	errs := []error{
+		New("error"),
+		Errorf("error%d", 1),
+		WithStack(io.EOF),
+		WithMessage(io.EOF, "addition"),
+		Annotate(New("error"), "error2"),
+		errTest,
+		errTest.GenWithStackByArgs("x"),
+		errTest.Wrap(io.EOF),
+		Join(New("error1"), WithMessage(io.EOF, "error2")),
+	}
+	// every verb except %+v, %-v and %#+v renders the message like fmt renders a string.
+	formats := []struct {
+		format, stringFormat string
+	}{
+		{"%s", "%s"},
+		{"%v", "%s"},
+		{"%#v", "%s"},
+		{"%q", "%q"},
+		{"%+q", "%+q"},
+		{"%#q", "%#q"},
+		{"%x", "%x"},
+		{"%X", "%X"},
+		{"% x", "% x"},
+		{"%30s", "%30s"},
+		{"%-30s|", "%-30s|"},
+		{"%.3s", "%.3s"},
+		{"%30v", "%30s"},
+		{"%.3v", "%.3s"},
+		{"%40q", "%40q"},
+	}
+
+	for i, err := range errs {
+		for _, f := range formats {
+			got := fmt.Sprintf(f.format, err)
+			want := fmt.Sprintf(f.stringFormat, err.Error())
+			if got != want {
+				t.Errorf("test %d: fmt.Sprintf(%q, %T):\n got: %q\nwant: %q", i+1, f.format, err, got, want)
+			}
+		}
+
+		got := fmt.Sprintf("%d", err)
+		want := fmt.Sprintf("%%!d(%T=%s)", err, err.Error())
+		if got != want {
+			t.Errorf("test %d: fmt.Sprintf(%%d, %T):\n got: %q\nwant: %q", i+1, err, got, want)
+		}
+
+		if got, want := fmt.Sprintf("%#+v", err), Tree(err); got != want {
+			t.Errorf("test %d: fmt.Sprintf(%%#+v, %T):\n got: %q\nwant: %q", i+1, err, got, want)
+		}
+	}
+}
+
+func TestFormatChain(t *testing.T) {
+	errTest := Normalize("named error: %s", RFCCodeText("Internal:Test"))
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{New("error"), "error"},
+		{WithStack(io.EOF), "EOF"},
+		{Annotate(Annotate(io.EOF, "error1"), "error2"), "error2\nerror1\nEOF"},
+		{WithMessage(errTest.Wrap(New("cause")).GenWithStackByArgs("x"), "outer"), "outer\n[Internal:Test]named error: x\ncause"},
+		{errTest.Wrap(errTest.Wrap(io.EOF)), "[Internal:Test]named error: %s\n[Internal:Test]named error: %s\nEOF"},
+		{Annotate(Join(New("error1"), WithMessage(io.EOF, "error2")), "joined"), "joined\nerror1\nerror2\nEOF"},
+		{Annotate(fmt.Errorf("foreign: %w", io.EOF), "error"), "error\nforeign: EOF"},
+	}
+	for i, tt := range tests {
+		if got := fmt.Sprintf("%-v", tt.err); got != tt.want {
+			t.Errorf("test %d: fmt.Sprintf(%%-v, err):\n got: %q\nwant: %q", i+1, got, tt.want)
+		}
+	}
+}
+
+func TestFormatJoinExtended(t *testing.T) {
+	err := Join(New("error1"), WithMessage(io.EOF, "error2"))
+	testFormatCompleteCompare(t, 0, err, "%+v", []string{
+		"- error1",
+		"  github.com/pingcap/errors.TestFormatJoinExtended\n" +
+			"  \t.+/pingcap/errors/format_test.go:\\d+",
+		"- EOF",
+		"  error2",
+	}, false)
+}
+
+func TestFormatMultilineExtended(t *testing.T) {
+	err := WithMessage(Join(fmt.Errorf("foreign\nerror"), Join(New("error1"), io.EOF)), "outer\nmessage")
+	testFormatCompleteCompare(t, 0, err, "%+v", []string{
+		"- foreign",
+		"    error",
+		"- - error1",
+		"    github.com/pingcap/errors.TestFormatMultilineExtended\n" +
+			"    \t.+/pingcap/errors/format_test.go:\\d+",
+		"  - EOF",
+		"outer",
+		"  message",
+	}, false)
+}
@@ -0,0 +1,49 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+// AsType returns the first error in the graph of err that has the type T.
+// Unlike errors.Cause(err).(T), it looks at every layer: the ones wrapped by
+// *Error, the members of Join and ErrorGroup included.
+//
+//	if terr, ok := errors.AsType[*errors.Error](err); ok {
+//	        // handle terr
+//	}
+func AsType[T error](err error) (T, bool) {
+	var found T
+	ok := walkAll(err, func(err error) bool {
+		t, ok := err.(T)
+		if ok {
+			found = t
+		}
+		return ok
+	})
+	return found, ok
+}
+
+// FindAll returns every error in the graph of err that has the type T,
+// in the order AsType visits them.
+func FindAll[T error](err error) []T {
+	var found []T
+	walkAll(err, func(err error) bool {
+		if t, ok := err.(T); ok {
+			found = append(found, t)
+		}
+		return false
+	})
+	return found
+}
@@ -0,0 +1,56 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package errors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsType(t *testing.T) {
+	e1 := Normalize("e1", RFCCodeText("e1"))
+	e2 := Normalize("e2", RFCCodeText("e2"))
+	// (*Error).Cause skips the e1 layer of this chain.
+	err := Annotate(e2.Wrap(e1.Wrap(fooError(100))), "annotated")
+
+	terr, ok := AsType[*Error](err)
+	require.True(t, ok)
+	require.Equal(t, ErrorID("e2"), terr.ID())
+
+	foo, ok := AsType[fooError](err)
+	require.True(t, ok)
+	require.Equal(t, fooError(100), foo)
+
+	_, ok = AsType[*errWalkTest](err)
+	require.False(t, ok)
+	_, ok = AsType[*Error](nil)
+	require.False(t, ok)
+
+	all := FindAll[*Error](err)
+	require.Len(t, all, 2)
+	require.Equal(t, ErrorID("e2"), all[0].ID())
+	require.Equal(t, ErrorID("e1"), all[1].ID())
+
+	joined := Join(io.EOF, Annotate(e1.GenWithStackByArgs(), "a"), &errWalkTest{sub: []error{e2}})
+	all = FindAll[*Error](joined)
+	require.Len(t, all, 2)
+	require.Equal(t, ErrorID("e1"), all[0].ID())
+	require.Equal(t, ErrorID("e2"), all[1].ID())
+	require.Nil(t, FindAll[fooError](joined))
+}
@@ -47,3 +47,36 @@ func WalkDeep(err error, visitor func(err error) bool) bool {
 
 	return false
 }
+
+// walkAll does a depth-first traversal like WalkDeep, but follows every layer of
+// the chain with DirectCause and also goes wide into Go 1.20 multi-errors like Join.
+func walkAll(err error, visitor func(err error) bool) bool {
+	if err == nil {
+		return false
+	}
+
+	if visitor(err) {
+		return true
+	}
+
+	// Go deep
+	if walkAll(DirectCause(err), visitor) {
+		return true
+	}
+
+	// Go wide
+	var errs []error
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		errs = x.Unwrap()
+	case ErrorGroup:
+		errs = x.Errors()
+	}
+	for _, err := range errs {
+		if walkAll(err, visitor) {
+			return true
+		}
+	}
+
+	return false
+}
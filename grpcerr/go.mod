@@ -0,0 +1,26 @@
+module github.com/pingcap/errors/grpcerr
+
+go 1.19
+
+// grpcerr needs FromRemote, first released in pingcap/errors v0.11.5: that root
+// release must be tagged before grpcerr is. The replace directive only applies
+// when working in this repository.
+replace github.com/pingcap/errors => ../
+
+require (
+	github.com/pingcap/errors v0.11.5
+	github.com/stretchr/testify v1.11.1
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237
+	google.golang.org/grpc v1.64.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	golang.org/x/net v0.22.0 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,288 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcerr converts the *errors.Error of github.com/pingcap/errors to and
+// from gRPC statuses, so that their codes survive a gRPC call.
+//
+// Every *errors.Error layer of the chain of an error is carried by an
+// errdetails.ErrorInfo detail of the status, the outermost first, with the RFC
+// code as reason, Domain as domain and the following metadata:
+//
+//	mysql_code  the MySQL error code, in decimal
+//	message     the message, as returned by GetMsg
+//	template    the message template, as returned by MessageTemplate
+//	arg.N       the N-th arg, formatted with %v
+//	cause       the message of the cause of the innermost layer, if it is not an *errors.Error
+//
+// Errors converted back satisfy Equal against their prototypes:
+//
+//	// server
+//	s := grpc.NewServer(grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor()))
+//
+//	// client
+//	_, err := client.Call(ctx, req)
+//	if ErrWriteConflict.Equal(grpcerr.FromError(err)) {
+//	    // retry
+//	}
+package grpcerr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain is the domain of the errdetails.ErrorInfo details added by ToStatus.
+const Domain = "github.com/pingcap/errors"
+
+const (
+	keyMySQLCode = "mysql_code"
+	keyMessage   = "message"
+	keyTemplate  = "template"
+	keyArgPrefix = "arg."
+	keyCause     = "cause"
+)
+
+// Option configures a Converter.
+type Option func(*Converter)
+
+// WithCode maps the errors having an *errors.Error layer with the ID of
+// prototype to the gRPC code c.
+func WithCode(prototype *errors.Error, c codes.Code) Option {
+	return func(conv *Converter) {
+		conv.byID[prototype.ID()] = c
+	}
+}
+
+// WithCategoryCode maps the errors of the given category, see errors.WithCategory,
+// to the gRPC code c.
+func WithCategoryCode(category string, c codes.Code) Option {
+	return func(conv *Converter) {
+		conv.byCategory[category] = c
+	}
+}
+
+// WithDefaultCode sets the gRPC code of the errors mapped by no other option.
+// It is codes.Unknown by default.
+func WithDefaultCode(c codes.Code) Option {
+	return func(conv *Converter) {
+		conv.defaultCode = c
+	}
+}
+
+// Converter converts errors to and from gRPC statuses. It is safe for concurrent
+// use once created.
+type Converter struct {
+	byID        map[errors.ErrorID]codes.Code
+	byCategory  map[string]codes.Code
+	defaultCode codes.Code
+}
+
+// NewConverter creates a Converter. The gRPC code of an error is picked from the
+// *errors.Error layers of its chain, the outermost first: the code set by
+// WithCode for the layer, or else the one set by WithCategoryCode for its
+// category. Errors without a matching layer get the code set by WithDefaultCode.
+func NewConverter(opts ...Option) *Converter {
+	c := &Converter{
+		byID:        map[errors.ErrorID]codes.Code{},
+		byCategory:  map[string]codes.Code{},
+		defaultCode: codes.Unknown,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+var defaultConverter = NewConverter()
+
+// ToStatus converts err with a Converter created without options.
+func ToStatus(err error) *status.Status {
+	return defaultConverter.ToStatus(err)
+}
+
+// FromError converts err with a Converter created without options.
+func FromError(err error) error {
+	return defaultConverter.FromError(err)
+}
+
+// FromStatus converts st with a Converter created without options.
+func FromStatus(st *status.Status) error {
+	return defaultConverter.FromStatus(st)
+}
+
+// UnaryServerInterceptor returns the interceptor of a Converter created without options.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return defaultConverter.UnaryServerInterceptor()
+}
+
+// UnaryClientInterceptor returns the interceptor of a Converter created without options.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return defaultConverter.UnaryClientInterceptor()
+}
+
+// layers returns the *errors.Error layers of the chain of err, the outermost first.
+func layers(err error) []*errors.Error {
+	var all []*errors.Error
+	for _, layer := range errors.Chain(err) {
+		if e, ok := layer.(*errors.Error); ok {
+			all = append(all, e)
+		}
+	}
+	return all
+}
+
+func (c *Converter) code(all []*errors.Error) codes.Code {
+	for _, e := range all {
+		if code, ok := c.byID[e.ID()]; ok {
+			return code
+		}
+		if code, ok := c.byCategory[e.Category()]; ok && e.Category() != "" {
+			return code
+		}
+	}
+	return c.defaultCode
+}
+
+// ToStatus converts err to a gRPC status, whose message is err.Error(). It
+// returns nil for nil. An error without *errors.Error layer is given the default
+// code, unless it already has a status, see status.FromError.
+func (c *Converter) ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	all := layers(err)
+	if len(all) == 0 {
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		return status.New(c.defaultCode, err.Error())
+	}
+	st := status.New(c.code(all), err.Error())
+	details := make([]protoadapt.MessageV1, 0, len(all))
+	for i, e := range all {
+		info := &errdetails.ErrorInfo{
+			Reason: string(e.RFCCode()),
+			Domain: Domain,
+			Metadata: map[string]string{
+				keyMySQLCode: strconv.Itoa(int(e.Code())),
+				keyMessage:   e.GetMsg(),
+				keyTemplate:  e.MessageTemplate(),
+			},
+		}
+		for j, arg := range e.Args() {
+			info.Metadata[keyArgPrefix+strconv.Itoa(j)] = fmt.Sprint(arg)
+		}
+		if i == len(all)-1 {
+			if cause := errors.DirectCause(e); cause != nil {
+				info.Metadata[keyCause] = cause.Error()
+			}
+		}
+		details = append(details, info)
+	}
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromError converts an error returned by a gRPC call back to the *errors.Error
+// it was converted from by ToStatus, wrapping the *errors.Error converted from
+// its inner layers. Errors without status or ErrorInfo details of Domain are
+// returned as is, nil included.
+func (c *Converter) FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if converted := c.FromStatus(st); converted != nil {
+		return converted
+	}
+	return err
+}
+
+// FromStatus converts st back to an *errors.Error, see FromError. It returns
+// nil if st has no ErrorInfo detail of Domain.
+func (c *Converter) FromStatus(st *status.Status) error {
+	var infos []*errdetails.ErrorInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == Domain {
+			infos = append(infos, info)
+		}
+	}
+	if len(infos) == 0 {
+		return nil
+	}
+	var e *errors.Error
+	for i := len(infos) - 1; i >= 0; i-- {
+		e = fromInfo(infos[i], e)
+	}
+	return e
+}
+
+// UnaryServerInterceptor returns an interceptor converting the errors returned
+// by handlers with ToStatus.
+func (c *Converter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, c.ToStatus(err).Err()
+		}
+		return resp, nil
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor converting the errors of calls
+// with FromError.
+func (c *Converter) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return c.FromError(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// fromInfo converts info back to an *errors.Error wrapping inner, or the
+// cause recorded in its metadata if inner is nil.
+func fromInfo(info *errdetails.ErrorInfo, inner *errors.Error) *errors.Error {
+	md := info.GetMetadata()
+	code, _ := strconv.Atoi(md[keyMySQLCode])
+	r := errors.RemoteError{
+		RFCCode:  errors.RFCErrorCode(info.GetReason()),
+		Code:     errors.ErrCode(code),
+		Template: md[keyTemplate],
+		Msg:      md[keyMessage],
+	}
+	for i := 0; ; i++ {
+		arg, ok := md[keyArgPrefix+strconv.Itoa(i)]
+		if !ok {
+			break
+		}
+		r.Args = append(r.Args, arg)
+	}
+	if inner != nil {
+		r.Cause = inner
+	} else if msg, ok := md[keyCause]; ok {
+		r.Cause = errors.NewNoStackError(msg)
+	}
+	return errors.FromRemote(r)
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcerr
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	errWriteConflict = errors.Normalize("write conflict, txnStartTS=%d, key=%s",
+		errors.RFCCodeText("tikv:kv:WriteConflict"), errors.MySQLErrorCode(9007))
+	errRegionUnavailable = errors.Normalize("region %d is unavailable",
+		errors.RFCCodeText("tikv:region:Unavailable"), errors.MySQLErrorCode(9005), errors.WithCategory("network"))
+	errTxn = errors.Normalize("transaction aborted", errors.RFCCodeText("tidb:txn:Aborted"), errors.MySQLErrorCode(8000))
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func dial(t *testing.T, c *Converter, err error) healthpb.HealthClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(c.UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(srv, &healthServer{err: err})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, dialErr := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(c.UnaryClientInterceptor()))
+	require.NoError(t, dialErr)
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := NewConverter(WithCode(errWriteConflict, codes.Aborted))
+	sent := errWriteConflict.GenWithStackByArgs(42, "k1")
+	_, err := dial(t, c, sent).Check(context.Background(), &healthpb.HealthCheckRequest{})
+
+	require.True(t, errWriteConflict.Equal(err))
+	e, ok := err.(*errors.Error)
+	require.True(t, ok)
+	require.Equal(t, sent.Error(), e.Error())
+	require.Equal(t, errWriteConflict.MessageTemplate(), e.MessageTemplate())
+	require.Equal(t, []interface{}{"42", "k1"}, e.Args())
+	require.Equal(t, errors.ErrCode(9007), e.Code())
+}
+
+func TestRoundTripChain(t *testing.T) {
+	c := NewConverter(WithCategoryCode("network", codes.Unavailable))
+	sent := errTxn.Wrap(errRegionUnavailable.Wrap(io.EOF).GenWithStackByArgs(3))
+
+	st := c.ToStatus(sent)
+	require.Equal(t, codes.Unavailable, st.Code())
+	require.Equal(t, sent.Error(), st.Message())
+	require.Len(t, st.Details(), 2)
+
+	_, err := dial(t, c, sent).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.True(t, errors.ContainsCode(err, errTxn))
+	require.True(t, errors.ContainsCode(err, errRegionUnavailable))
+	require.Equal(t, []errors.RFCErrorCode{"tidb:txn:Aborted", "tikv:region:Unavailable"}, errors.Codes(err))
+	require.Equal(t, sent.Error(), err.Error())
+}
+
+func TestCodeMapping(t *testing.T) {
+	c := NewConverter(
+		WithCode(errWriteConflict, codes.Aborted),
+		WithCategoryCode("network", codes.Unavailable),
+		WithDefaultCode(codes.Internal))
+
+	require.Nil(t, c.ToStatus(nil))
+	require.Equal(t, codes.Aborted, c.ToStatus(errWriteConflict.FastGenByArgs(1, "k")).Code())
+	// the outermost layer takes precedence.
+	require.Equal(t, codes.Aborted, c.ToStatus(errWriteConflict.Wrap(errRegionUnavailable.FastGenByArgs(1))).Code())
+	require.Equal(t, codes.Unavailable, c.ToStatus(errTxn.Wrap(errRegionUnavailable.FastGenByArgs(1))).Code())
+	require.Equal(t, codes.Internal, c.ToStatus(errTxn.FastGenByArgs()).Code())
+	require.Equal(t, codes.Internal, c.ToStatus(io.EOF).Code())
+	require.Equal(t, codes.NotFound, c.ToStatus(status.Error(codes.NotFound, "missing")).Code())
+	require.Equal(t, codes.Unknown, ToStatus(errTxn.FastGenByArgs()).Code())
+}
+
+func TestFromErrorForeign(t *testing.T) {
+	require.Nil(t, FromError(nil))
+	require.Equal(t, io.EOF, FromError(io.EOF))
+	plain := status.Error(codes.NotFound, "missing")
+	require.Equal(t, plain, FromError(plain))
+	require.Nil(t, FromStatus(status.New(codes.NotFound, "missing")))
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httperr writes the errors of github.com/pingcap/errors as RFC 7807
+// problem documents in HTTP responses, see errors.ToProblem.
+package httperr
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pingcap/errors"
+)
+
+// WriteProblem writes the problem document of err, see errors.ToProblem, as the
+// response of an HTTP handler. Problems without title are given the text of
+// their status. It writes nothing for nil.
+func WriteProblem(w http.ResponseWriter, err error) error {
+	p := errors.ToProblem(err)
+	if p == nil {
+		return nil
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	data, merr := json.Marshal(p)
+	if merr != nil {
+		return errors.Trace(merr)
+	}
+	w.Header().Set("Content-Type", errors.ProblemContentType)
+	w.WriteHeader(p.Status)
+	_, werr := w.Write(data)
+	return errors.Trace(werr)
+}
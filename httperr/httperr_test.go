@@ -0,0 +1,66 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProblem(t *testing.T) {
+	errNotFound := errors.Normalize("table %s.%s doesn't exist", errors.RFCCodeText("test-httperr:schema:TableNotExists"),
+		errors.MySQLErrorCode(1146), errors.RedactArgs([]int{1}))
+	errors.SetProblemStatus(errNotFound.RFCCode(), http.StatusNotFound)
+	defer errors.SetProblemStatus(errNotFound.RFCCode(), 0)
+
+	var handlerErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, WriteProblem(w, handlerErr))
+	}))
+	defer srv.Close()
+
+	get := func() (*http.Response, *errors.Problem) {
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		p := &errors.Problem{}
+		require.NoError(t, json.Unmarshal(data, p))
+		return resp, p
+	}
+
+	handlerErr = errors.Annotate(errNotFound.GenWithStackByArgs("test", "secret"), "load table")
+	resp, p := get()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Equal(t, errors.ProblemContentType, resp.Header.Get("Content-Type"))
+	require.Equal(t, "table %s.%s doesn't exist", p.Title)
+	require.Equal(t, http.StatusNotFound, p.Status)
+	require.Equal(t, "table test.? doesn't exist", p.Detail)
+	require.True(t, errNotFound.Equal(errors.FromProblem(p)))
+
+	handlerErr = io.EOF
+	resp, p = get()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Equal(t, "about:blank", p.Type)
+	require.Equal(t, "Internal Server Error", p.Title)
+	require.Equal(t, "EOF", p.Detail)
+	require.Nil(t, errors.FromProblem(p))
+}
@@ -13,6 +13,12 @@
 
 package errors
 
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
 // Join returns an error that wraps the given errors.
 // Any nil error values are discarded.
 // Join returns nil if every value in errs is nil.
@@ -60,3 +66,21 @@ func (e *joinError) Error() string {
 func (e *joinError) Unwrap() []error {
 	return e.errs
 }
+
+func (e *joinError) Format(s fmt.State, verb rune) { formatError(s, verb, e) }
+
+// formatExtended writes the %+v form of each error, one after another. The first
+// line of each error is prefixed by joinMemberPrefix and the other ones are
+// indented, so that the errors can be told apart.
+func (e *joinError) formatExtended(s fmt.State) {
+	var b strings.Builder
+	for i, err := range e.errs {
+		if i > 0 {
+			io.WriteString(s, "\n")
+		}
+		b.Reset()
+		writeExtended(&b, err)
+		io.WriteString(s, joinMemberPrefix)
+		io.WriteString(s, strings.ReplaceAll(b.String(), "\n", "\n"+continuationIndent))
+	}
+}
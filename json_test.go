@@ -0,0 +1,175 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errJSONDup   = Normalize("Duplicate entry '%s' for key %d", RFCCodeText("test-json:kv:Duplicate"), MySQLErrorCode(1062))
+	errJSONInner = Normalize("region %d unavailable", RFCCodeText("test-json:kv:Region"), MySQLErrorCode(9005))
+)
+
+func jsonRoundTrip(t *testing.T, err error) *Error {
+	var e *Error
+	require.True(t, stderrors.As(err, &e))
+	data, jerr := json.Marshal(e)
+	require.NoError(t, jerr)
+	decoded := &Error{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	return decoded
+}
+
+func TestJSONRoundTripArgs(t *testing.T) {
+	err := errJSONDup.GenWithStackByArgs("100%", 2)
+	decoded := jsonRoundTrip(t, err)
+
+	require.Equal(t, errJSONDup.MessageTemplate(), decoded.MessageTemplate())
+	require.Equal(t, []interface{}{"100%", "2"}, decoded.Args())
+	require.Equal(t, "Duplicate entry '100%' for key 2", decoded.GetMsg())
+	require.Equal(t, err.Error(), decoded.Error())
+	require.True(t, errJSONDup.Equal(decoded))
+
+	// the decoded error can generate new ones.
+	require.Equal(t, "[test-json:kv:Duplicate]Duplicate entry 'k' for key 3", decoded.GenWithStackByArgs("k", 3).Error())
+	require.Equal(t, "[test-json:kv:Duplicate]Duplicate entry 'k' for key 4", decoded.FastGenByArgs("k", 4).Error())
+}
+
+func TestJSONRoundTripCause(t *testing.T) {
+	err := errJSONDup.Wrap(errJSONInner.GenWithStackByArgs(7))
+	decoded := jsonRoundTrip(t, err)
+	require.Equal(t, err.Error(), decoded.Error())
+	inner, ok := DirectCause(decoded).(*Error)
+	require.True(t, ok)
+	require.True(t, errJSONInner.Equal(inner))
+	require.Equal(t, []interface{}{"7"}, inner.Args())
+
+	err = errJSONDup.Wrap(io.EOF)
+	decoded = jsonRoundTrip(t, err)
+	require.Equal(t, err.Error(), decoded.Error())
+	require.Equal(t, "EOF", DirectCause(decoded).Error())
+	require.False(t, HasStack(DirectCause(decoded)))
+}
+
+func TestJSONCompatibility(t *testing.T) {
+	// payloads of older versions.
+	old := `{"class":0,"code":1062,"message":"Duplicate entry '1' for key 2","rfccode":"test-json:kv:Duplicate"}`
+	var decoded Error
+	require.NoError(t, json.Unmarshal([]byte(old), &decoded))
+	require.Equal(t, "Duplicate entry '1' for key 2", decoded.MessageTemplate())
+	require.Nil(t, decoded.Args())
+	require.Equal(t, "[test-json:kv:Duplicate]Duplicate entry '1' for key 2", decoded.Error())
+	require.True(t, errJSONDup.Equal(&decoded))
+
+	// older versions decoding new payloads.
+	data, err := json.Marshal(errJSONDup.Wrap(io.EOF).GenWithStackByArgs("1", 2).(*withStack).error)
+	require.NoError(t, err)
+	var legacy struct {
+		Class   int    `json:"class"`
+		Code    int    `json:"code"`
+		Msg     string `json:"message"`
+		RFCCode string `json:"rfccode"`
+	}
+	require.NoError(t, json.Unmarshal(data, &legacy))
+	require.Equal(t, 1062, legacy.Code)
+	require.Equal(t, "Duplicate entry '1' for key 2", legacy.Msg)
+	require.Equal(t, "test-json:kv:Duplicate", legacy.RFCCode)
+}
+
+func TestFromRemote(t *testing.T) {
+	e := FromRemote(RemoteError{
+		RFCCode:  "test-json:kv:Duplicate",
+		Code:     1062,
+		Template: "Duplicate entry '%s' for key %d",
+		Msg:      "Duplicate entry '1' for key 2",
+		Args:     []string{"1", "2"},
+		Cause:    io.EOF,
+	})
+	require.Equal(t, errJSONDup.ID(), e.ID())
+	require.Equal(t, errJSONDup.Code(), e.Code())
+	require.Equal(t, "[test-json:kv:Duplicate]Duplicate entry '1' for key 2: EOF", e.Error())
+	require.Equal(t, []interface{}{"1", "2"}, e.Args())
+	require.Equal(t, io.EOF, DirectCause(e))
+	require.Equal(t, "[test-json:kv:Duplicate]Duplicate entry '3' for key 4: EOF", e.FastGenByArgs("3", 4).Error())
+
+	decoded := jsonRoundTrip(t, e)
+	require.Equal(t, e.Error(), decoded.Error())
+	require.Equal(t, e.MessageTemplate(), decoded.MessageTemplate())
+
+	// without template.
+	e = FromRemote(RemoteError{Code: 1105, Msg: "unknown 100%"})
+	require.Equal(t, "[1105]unknown 100%", e.Error())
+}
+
+func TestJSONRoundTripAnnotatedCause(t *testing.T) {
+	firstError := func(err error) *Error {
+		for _, layer := range Chain(err) {
+			if e, ok := layer.(*Error); ok {
+				return e
+			}
+		}
+		return nil
+	}
+
+	err := errJSONDup.Wrap(Annotate(errJSONInner.FastGenByArgs(1), "ctx"))
+	decoded := jsonRoundTrip(t, err)
+	require.Equal(t, err.Error(), decoded.Error())
+	annotation := DirectCause(decoded)
+	require.Equal(t, "ctx", annotation.(*withMessage).GetSelfMsg())
+	inner := firstError(DirectCause(annotation))
+	require.NotNil(t, inner)
+	require.True(t, errJSONInner.Equal(inner))
+	require.Equal(t, ErrCode(9005), inner.Code())
+	require.Equal(t, []interface{}{"1"}, inner.Args())
+	require.True(t, HasCode(decoded, errJSONInner.ID()))
+
+	err = errJSONDup.Wrap(fmt.Errorf("ctx: %w", errJSONInner.FastGenByArgs(2)))
+	decoded = jsonRoundTrip(t, err)
+	require.Equal(t, err.Error(), decoded.Error())
+	require.True(t, errJSONInner.Equal(firstError(DirectCause(decoded))))
+
+	// layers whose own message is unknown end the chain.
+	err = errJSONDup.Wrap(fmt.Errorf("%w (ctx)", errJSONInner.FastGenByArgs(3)))
+	decoded = jsonRoundTrip(t, err)
+	require.Equal(t, err.Error(), decoded.Error())
+	require.Nil(t, firstError(DirectCause(decoded)))
+}
+
+func TestUnmarshalJSONGenerated(t *testing.T) {
+	errPrototype := Normalize("conflict on %s", RFCCodeText("test-json:kv:Conflict"), MySQLErrorCode(9007),
+		SQLState("40001"), RedactArgs([]int{0}), Pooled())
+	data, err := json.Marshal(Normalize("other %s", RFCCodeText("test-json:kv:Other"), MySQLErrorCode(9999)).FastGenByArgs("k"))
+	require.NoError(t, err)
+
+	generated := errPrototype.FastGenByArgs("k").(*Error)
+	require.NoError(t, json.Unmarshal(data, generated))
+	require.Equal(t, "HY000", generated.SQLState())
+	require.Equal(t, "other k", generated.RedactedMsg())
+	require.Nil(t, generated.pooled)
+	Release(generated)
+	require.Equal(t, "[test-json:kv:Other]other k", generated.Error())
+
+	// the instance shared without args is left untouched.
+	errNoArgs := Normalize("write conflict", RFCCodeText("test-json:kv:NoArgs"))
+	shared := errNoArgs.FastGenByArgs().(*Error)
+	require.Error(t, json.Unmarshal(data, shared))
+	require.Equal(t, "[test-json:kv:NoArgs]write conflict", errNoArgs.FastGenByArgs().Error())
+}
@@ -32,6 +32,7 @@ func Annotate(err error, message string) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
@@ -52,6 +53,7 @@ func Annotatef(err error, format string, args ...interface{}) error {
 	return &withStack{
 		err,
 		callers(),
+		captureStackMeta(),
 	}
 }
 
@@ -92,6 +94,7 @@ func SuspendStack(err error) error {
 	return &withStack{
 		err,
 		&emptyStack,
+		nil,
 	}
 }
 
@@ -101,11 +104,16 @@ func clearStack(err error) (cleared bool) {
 		return clearStack(typedErr.Cause())
 	case *fundamental:
 		typedErr.stack = &emptyStack
+		typedErr.meta = nil
 		return true
 	case *withStack:
 		typedErr.stack = &emptyStack
+		typedErr.meta = nil
 		clearStack(typedErr.Cause())
 		return true
+	case *Error:
+		// an *Error has no stack of its own, nor has its cause if there is none.
+		return typedErr.cause == nil
 	default:
 		return false
 	}
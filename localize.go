@@ -0,0 +1,166 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	catalogsMu sync.RWMutex
+	// catalogs maps a locale to the message templates of the errors, by RFC code.
+	catalogs = map[string]map[RFCErrorCode]string{}
+	// prototypeArgs and referenceArgs map RFC codes to the number of args of
+	// their default templates: the ones of the prototypes returned by Normalize,
+	// and the ones given to AddReferenceTemplates.
+	prototypeArgs = map[RFCErrorCode]int{}
+	referenceArgs = map[RFCErrorCode]int{}
+)
+
+// registerPrototypeArgs records the number of args of the template of a
+// prototype returned by Normalize. The first prototype of an RFC code wins.
+func registerPrototypeArgs(e *Error) {
+	if e.codeText == "" && e.code == 0 {
+		return
+	}
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	if _, ok := prototypeArgs[e.RFCCode()]; !ok {
+		prototypeArgs[e.RFCCode()] = e.tmpl.argCount
+	}
+}
+
+// AddReferenceTemplates adds default message templates, by RFC code, to the
+// ones the catalogs are checked against by LoadMessageCatalog, e.g. the ones of
+// the file written by errdoc-gen. They are useful for the errors whose
+// prototypes are not created yet, or in the programs not creating them.
+func AddReferenceTemplates(templates map[string]string) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	for code, tmpl := range templates {
+		referenceArgs[RFCErrorCode(code)] = parseTemplate(tmpl).argCount
+	}
+}
+
+// LoadMessageCatalog loads the message templates of errors in the given locale,
+// like "zh" or "ja-JP", by RFC code:
+//
+//	errors.LoadMessageCatalog("zh", map[string]string{
+//		"tikv:kv:WriteConflict": "写冲突, txnStartTS=%d",
+//	})
+//
+// The catalogs in the TOML format written by errdoc-gen are loaded by the
+// github.com/pingcap/errors/catalog package. Loading several catalogs for the
+// same locale merges them.
+//
+// A template must take as many args as the default template of the same RFC
+// code: the template of the first prototype returned by Normalize with that
+// code, or else the one given to AddReferenceTemplates, or else the template
+// of a catalog already loaded for another locale. If one does not, nothing is
+// loaded and the mismatches are returned.
+func LoadMessageCatalog(locale string, templates map[string]string) error {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	expected := func(code RFCErrorCode) (int, bool) {
+		if n, ok := prototypeArgs[code]; ok {
+			return n, true
+		}
+		if n, ok := referenceArgs[code]; ok {
+			return n, true
+		}
+		for l, other := range catalogs {
+			if tmpl, ok := other[code]; ok && l != locale {
+				return parseTemplate(tmpl).argCount, true
+			}
+		}
+		return 0, false
+	}
+
+	loaded := make(map[RFCErrorCode]string, len(templates))
+	var mismatches []string
+	for code, tmpl := range templates {
+		if want, ok := expected(RFCErrorCode(code)); ok {
+			if got := parseTemplate(tmpl).argCount; got != want {
+				mismatches = append(mismatches, fmt.Sprintf("%s takes %d args instead of %d", code, got, want))
+			}
+		}
+		loaded[RFCErrorCode(code)] = tmpl
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		return Errorf("invalid message catalog for locale %s: %s", locale, strings.Join(mismatches, ", "))
+	}
+
+	catalog := catalogs[locale]
+	if catalog == nil {
+		catalog = make(map[RFCErrorCode]string, len(loaded))
+		catalogs[locale] = catalog
+	}
+	for code, tmpl := range loaded {
+		catalog[code] = tmpl
+	}
+	return nil
+}
+
+// LocalizedMsg returns the message of the outermost *Error of the chain of err,
+// like GetMsg but rendered from its template in the given locale. If no template
+// is loaded for the locale, the one of its language is used, e.g. "zh" for
+// "zh-CN", and else the default template of the error.
+//
+// Errors decoded by UnmarshalJSON keep the message rendered by the sender, as
+// their args are strings. Errors without *Error return err.Error().
+func LocalizedMsg(err error, locale string) string {
+	if err == nil {
+		return ""
+	}
+	var e *Error
+	for _, layer := range Chain(err) {
+		if x, ok := layer.(*Error); ok {
+			e = x
+			break
+		}
+	}
+	if e == nil {
+		return err.Error()
+	}
+	if e.rendered {
+		return e.GetMsg()
+	}
+	tmpl, ok := lookupTemplate(e.RFCCode(), locale)
+	if !ok {
+		return e.GetMsg()
+	}
+	if len(e.args) > 0 {
+		return fmt.Sprintf(tmpl, e.args...)
+	}
+	return tmpl
+}
+
+func lookupTemplate(code RFCErrorCode, locale string) (string, bool) {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+	for {
+		if tmpl, ok := catalogs[locale][code]; ok {
+			return tmpl, true
+		}
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			return "", false
+		}
+		locale = locale[:i]
+	}
+}
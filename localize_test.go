@@ -0,0 +1,96 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizedMsg(t *testing.T) {
+	forgetComponent(t, "test-l10n")
+	kv := NewComponent("test-l10n").Class("kv")
+	errConflict := kv.Define(9007, "Write conflict, txnStartTS=%d, key=%s")
+	errBusy := kv.Define(9003, "TiKV server is busy")
+	errOther := kv.Define(9004, "other error %d")
+
+	require.NoError(t, LoadMessageCatalog("zh", map[string]string{
+		"test-l10n:kv:9007": "写冲突, txnStartTS=%d, key=%s",
+		"test-l10n:kv:9003": "TiKV 服务器繁忙",
+	}))
+
+	err := Annotate(errConflict.GenWithStackByArgs(42, "k"), "commit")
+	require.Equal(t, "写冲突, txnStartTS=42, key=k", LocalizedMsg(err, "zh"))
+	require.Equal(t, "写冲突, txnStartTS=42, key=k", LocalizedMsg(err, "zh-CN"))
+	require.Equal(t, "Write conflict, txnStartTS=42, key=k", LocalizedMsg(err, "ja"))
+	require.Equal(t, "TiKV 服务器繁忙", LocalizedMsg(errBusy.FastGenByArgs(), "zh_TW"))
+	require.Equal(t, "other error 1", LocalizedMsg(errOther.FastGenByArgs(1), "zh"))
+	require.Equal(t, "EOF", LocalizedMsg(io.EOF, "zh"))
+	require.Equal(t, "", LocalizedMsg(nil, "zh"))
+
+	// decoded errors keep the message rendered by the sender.
+	data, jerr := json.Marshal(errConflict.FastGenByArgs(1, "k"))
+	require.NoError(t, jerr)
+	var decoded Error
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, "Write conflict, txnStartTS=1, key=k", LocalizedMsg(&decoded, "zh"))
+
+	// arg count mismatches with the registered errors.
+	ja := map[string]string{
+		"test-l10n:kv:9007": "書き込み競合 txnStartTS=%d",
+		"test-l10n:kv:9003": "ビジー",
+	}
+	require.ErrorContains(t, LoadMessageCatalog("ja", ja), "test-l10n:kv:9007 takes 1 args instead of 2")
+	require.Equal(t, "TiKV server is busy", LocalizedMsg(errBusy.FastGenByArgs(), "ja"))
+
+	// and with the other locales.
+	require.NoError(t, LoadMessageCatalog("fr", map[string]string{"test-l10n:kv:9998": "%d %s"}))
+	require.ErrorContains(t, LoadMessageCatalog("ja", map[string]string{"test-l10n:kv:9998": "%d"}),
+		"test-l10n:kv:9998 takes 1 args instead of 2")
+}
+
+func TestTemplateArgCount(t *testing.T) {
+	for tmpl, n := range map[string]int{
+		"":                0,
+		"100%% sure":      0,
+		"%d%%":            1,
+		"%s and %-5.2f":   2,
+		"%*d":             2,
+		"%[2]s %[1]s":     2,
+		"%[3]d then %s":   4,
+		"%v %":            1,
+		"Duplicate '%s'!": 1,
+		"写冲突 %d, key=%s":  2,
+	} {
+		require.Equal(t, n, parseTemplate(tmpl).argCount, tmpl)
+	}
+}
+
+func TestMessageCatalogReference(t *testing.T) {
+	// plain prototypes are checked against from the first locale loaded.
+	errPlain := Normalize("region %d unavailable", RFCCodeText("test-l10n:kv:Region"))
+	require.ErrorContains(t, LoadMessageCatalog("zh", map[string]string{"test-l10n:kv:Region": "区域不可用"}),
+		"test-l10n:kv:Region takes 0 args instead of 1")
+	require.NoError(t, LoadMessageCatalog("zh", map[string]string{"test-l10n:kv:Region": "区域 %d 不可用"}))
+	require.Equal(t, "区域 7 不可用", LocalizedMsg(errPlain.FastGenByArgs(7), "zh"))
+
+	// and so are the errors of the reference templates.
+	AddReferenceTemplates(map[string]string{"test-l10n:kv:NotCreated": "table %s.%s not found"})
+	require.ErrorContains(t, LoadMessageCatalog("zh", map[string]string{"test-l10n:kv:NotCreated": "表 %s 不存在"}),
+		"test-l10n:kv:NotCreated takes 1 args instead of 2")
+	require.NoError(t, LoadMessageCatalog("zh", map[string]string{"test-l10n:kv:NotCreated": "表 %s.%s 不存在"}))
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/binary"
+)
+
+const (
+	// mysqlErrHeader is the first byte of an ERR packet.
+	mysqlErrHeader = 0xff
+	// mysqlSQLStateMarker precedes the SQLSTATE in the ERR packets of the 4.1 protocol.
+	mysqlSQLStateMarker = '#'
+	// mysqlUnknownError is ER_UNKNOWN_ERROR, sent for errors without MySQL code.
+	mysqlUnknownError = 1105
+	// DefaultSQLState is the SQLSTATE of errors without a more specific one:
+	// "general error".
+	DefaultSQLState = "HY000"
+	// sqlStateLen is the length of a SQLSTATE.
+	sqlStateLen = 5
+	// maxMySQLErrorCode is the largest error code an ERR packet can carry.
+	maxMySQLErrorCode = 0xffff
+)
+
+// defaultSQLStates maps well-known MySQL error codes to their SQLSTATE.
+var defaultSQLStates = map[ErrCode]string{
+	1040: "08004", // ER_CON_COUNT_ERROR
+	1043: "08S01", // ER_HANDSHAKE_ERROR
+	1044: "42000", // ER_DBACCESS_DENIED_ERROR
+	1045: "28000", // ER_ACCESS_DENIED_ERROR
+	1046: "3D000", // ER_NO_DB_ERROR
+	1047: "08S01", // ER_UNKNOWN_COM_ERROR
+	1048: "23000", // ER_BAD_NULL_ERROR
+	1049: "42000", // ER_BAD_DB_ERROR
+	1050: "42S01", // ER_TABLE_EXISTS_ERROR
+	1051: "42S02", // ER_BAD_TABLE_ERROR
+	1052: "23000", // ER_NON_UNIQ_ERROR
+	1054: "42S22", // ER_BAD_FIELD_ERROR
+	1060: "42S21", // ER_DUP_FIELDNAME
+	1061: "42000", // ER_DUP_KEYNAME
+	1062: "23000", // ER_DUP_ENTRY
+	1064: "42000", // ER_PARSE_ERROR
+	1066: "42000", // ER_NONUNIQ_TABLE
+	1091: "42000", // ER_CANT_DROP_FIELD_OR_KEY
+	1142: "42000", // ER_TABLEACCESS_DENIED_ERROR
+	1146: "42S02", // ER_NO_SUCH_TABLE
+	1149: "42000", // ER_SYNTAX_ERROR
+	1213: "40001", // ER_LOCK_DEADLOCK
+	1216: "23000", // ER_NO_REFERENCED_ROW
+	1217: "23000", // ER_ROW_IS_REFERENCED
+	1227: "42000", // ER_SPECIFIC_ACCESS_DENIED_ERROR
+	1264: "22003", // ER_WARN_DATA_OUT_OF_RANGE
+	1292: "22007", // ER_TRUNCATED_WRONG_VALUE
+	1317: "70100", // ER_QUERY_INTERRUPTED
+	1365: "22012", // ER_DIVISION_BY_ZERO
+	1406: "22001", // ER_DATA_TOO_LONG
+	1451: "23000", // ER_ROW_IS_REFERENCED_2
+	1452: "23000", // ER_NO_REFERENCED_ROW_2
+	1690: "22003", // ER_DATA_OUT_OF_RANGE
+}
+
+// SQLState returns a NormalizeOption to set the SQLSTATE of an error, a string
+// of 5 characters sent to MySQL clients along with the MySQL error code. States
+// of another length are ignored.
+func SQLState(state string) NormalizeOption {
+	return func(e *Error) {
+		e.def.sqlState = state
+	}
+}
+
+// SQLState returns the SQLSTATE of e: the one set by the SQLState option, or
+// else the standard one of its MySQL error code if it is well-known, or else
+// DefaultSQLState.
+func (e *Error) SQLState() string {
+	e.checkReleased()
+	if state := e.definition().sqlState; len(state) == sqlStateLen {
+		return state
+	}
+	if state, ok := defaultSQLStates[e.code]; ok {
+		return state
+	}
+	return DefaultSQLState
+}
+
+// EncodeMySQLErrPacket encodes err as the payload of an ERR packet of the MySQL
+// client/server protocol 4.1: the 0xff header, the MySQL error code in 2 bytes,
+// '#' followed by the SQLSTATE and the message. The 4 bytes header of the packet,
+// with its length and sequence id, is left to the caller.
+//
+// The outermost *Error of the chain of err is encoded, with its message redacted
+// according to RedactLogEnabled and its SQLState. Its code is encoded as
+// ER_UNKNOWN_ERROR (1105) if it has no MySQL error code or if it does not fit in
+// 2 bytes. Errors without *Error are encoded as ER_UNKNOWN_ERROR, with
+// DefaultSQLState and the message err.Error().
+func EncodeMySQLErrPacket(err error) []byte {
+	code, state, msg := uint16(mysqlUnknownError), DefaultSQLState, ""
+	var e *Error
+	for _, layer := range Chain(err) {
+		if x, ok := layer.(*Error); ok {
+			e = x
+			break
+		}
+	}
+	switch {
+	case e != nil:
+		if e.code > 0 && e.code <= maxMySQLErrorCode {
+			code = uint16(e.code)
+		}
+		state = e.SQLState()
+		msg = e.redactedMsg(RedactLogEnabled.Load())
+	case err != nil:
+		msg = err.Error()
+	}
+
+	data := make([]byte, 0, 4+sqlStateLen+len(msg))
+	data = append(data, mysqlErrHeader)
+	data = append(data, byte(code), byte(code>>8))
+	data = append(data, mysqlSQLStateMarker)
+	data = append(data, state...)
+	data = append(data, msg...)
+	return data
+}
+
+// DecodeMySQLErrPacket decodes the payload of an ERR packet, like the ones
+// encoded by EncodeMySQLErrPacket. Payloads without SQLSTATE, sent by the
+// protocols older than 4.1, are accepted too.
+//
+// The decoded *Error has the RFC code of the error defined with the same MySQL
+// error code by a Component, see RegisteredErrors, so that it is equal to it.
+// If several errors with different RFC codes are defined with that MySQL code,
+// the decoded *Error has no RFC code, as the packet can not tell them apart.
+// Its message template is the decoded message.
+func DecodeMySQLErrPacket(data []byte) (*Error, error) {
+	if len(data) < 3 || data[0] != mysqlErrHeader {
+		return nil, Errorf("malformed MySQL ERR packet % x", data)
+	}
+	e := &Error{code: ErrCode(binary.LittleEndian.Uint16(data[1:3])), def: &errorDef{}}
+	data = data[3:]
+	if len(data) > sqlStateLen && data[0] == mysqlSQLStateMarker {
+		e.def.sqlState = string(data[1 : 1+sqlStateLen])
+		data = data[1+sqlStateLen:]
+	}
+	e.message = string(data)
+	e.resolveAliases()
+	if codeText, ok := lookupMySQLCode(e.code); ok {
+		e.codeText = codeText
+	}
+	return e, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLState(t *testing.T) {
+	require.Equal(t, "23000", Normalize("dup", MySQLErrorCode(1062)).SQLState())
+	require.Equal(t, "HY000", Normalize("unknown", MySQLErrorCode(9999)).SQLState())
+	require.Equal(t, "HY000", Normalize("no code").SQLState())
+	require.Equal(t, "40001", Normalize("conflict", MySQLErrorCode(9007), SQLState("40001")).SQLState())
+	require.Equal(t, "23000", Normalize("dup", MySQLErrorCode(1062), SQLState("42")).SQLState())
+	require.Equal(t, "HY000", Normalize("long", SQLState("HY0000")).SQLState())
+}
+
+func TestMySQLErrPacket(t *testing.T) {
+	forgetComponent(t, "test-packet")
+	errDup := NewComponent("test-packet").Class("kv").Define(1062, "Duplicate entry '%s' for key '%s'", RedactArgs([]int{0}))
+
+	err := Annotate(errDup.GenWithStackByArgs("secret", "PRIMARY"), "insert")
+	data := EncodeMySQLErrPacket(err)
+	require.Equal(t, append([]byte{0xff, 0x26, 0x04, '#', '2', '3', '0', '0', '0'},
+		"Duplicate entry 'secret' for key 'PRIMARY'"...), data)
+
+	decoded, derr := DecodeMySQLErrPacket(data)
+	require.NoError(t, derr)
+	require.Equal(t, ErrCode(1062), decoded.Code())
+	require.Equal(t, "23000", decoded.SQLState())
+	require.Equal(t, "Duplicate entry 'secret' for key 'PRIMARY'", decoded.GetMsg())
+	require.Equal(t, errDup.RFCCode(), decoded.RFCCode())
+	require.True(t, errDup.Equal(decoded))
+
+	RedactLogEnabled.Store(RedactLogEnable)
+	data = EncodeMySQLErrPacket(errDup.FastGen("Duplicate entry '%s' for key '%s'", "secret", "PRIMARY"))
+	RedactLogEnabled.Store(RedactLogDisable)
+	decoded, derr = DecodeMySQLErrPacket(data)
+	require.NoError(t, derr)
+	require.Equal(t, "Duplicate entry '?' for key 'PRIMARY'", decoded.GetMsg())
+
+	// errors without *Error.
+	decoded, derr = DecodeMySQLErrPacket(EncodeMySQLErrPacket(io.EOF))
+	require.NoError(t, derr)
+	require.Equal(t, ErrCode(1105), decoded.Code())
+	require.Equal(t, "HY000", decoded.SQLState())
+	require.Equal(t, "EOF", decoded.GetMsg())
+
+	// packets of the protocols older than 4.1.
+	decoded, derr = DecodeMySQLErrPacket([]byte{0xff, 0x7a, 0x04, 'n', 'o', ' ', 't', 'a', 'b', 'l', 'e'})
+	require.NoError(t, derr)
+	require.Equal(t, ErrCode(1146), decoded.Code())
+	require.Equal(t, "42S02", decoded.SQLState())
+	require.Equal(t, "no table", decoded.GetMsg())
+
+	// codes which do not fit in the packet, and invalid states.
+	decoded, derr = DecodeMySQLErrPacket(EncodeMySQLErrPacket(Normalize("too large", MySQLErrorCode(70000), SQLState("42"))))
+	require.NoError(t, derr)
+	require.Equal(t, ErrCode(1105), decoded.Code())
+	require.Equal(t, "HY000", decoded.SQLState())
+	require.Equal(t, "too large", decoded.GetMsg())
+
+	_, derr = DecodeMySQLErrPacket([]byte{0x00, 0x01, 0x02})
+	require.Error(t, derr)
+	_, derr = DecodeMySQLErrPacket([]byte{0xff, 0x01})
+	require.Error(t, derr)
+}
+
+func TestMySQLErrPacketDuplicateCode(t *testing.T) {
+	forgetComponent(t, "test-packet-a")
+	forgetComponent(t, "test-packet-b")
+	errA := NewComponent("test-packet-a").Class("kv").Define(9301, "busy")
+	errUnique := NewComponent("test-packet-a").Class("kv").Define(9302, "unique")
+	errB := NewComponent("test-packet-b").Class("kv").Define(9301, "busy")
+
+	// the packet can not tell errA and errB apart.
+	decoded, derr := DecodeMySQLErrPacket(EncodeMySQLErrPacket(errB.FastGenByArgs()))
+	require.NoError(t, derr)
+	require.Equal(t, ErrCode(9301), decoded.Code())
+	require.Equal(t, RFCErrorCode("9301"), decoded.RFCCode())
+	require.False(t, errA.Equal(decoded))
+	require.False(t, errB.Equal(decoded))
+
+	decoded, derr = DecodeMySQLErrPacket(EncodeMySQLErrPacket(errUnique.FastGenByArgs()))
+	require.NoError(t, derr)
+	require.True(t, errUnique.Equal(decoded))
+}
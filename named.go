@@ -0,0 +1,267 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NamedTemplate returns a NormalizeOption making the message a template with
+// named parameters instead of a printf template:
+//
+//	var ErrDupEntry = errors.Normalize("Duplicate entry {entry} for key {key}",
+//	    errors.NamedTemplate(), errors.RedactNames("entry"))
+//
+//	err := ErrDupEntry.GenWithStackByNamed(errors.Named("entry", "1", "key", "PRIMARY"))
+//
+// A parameter is written {name}, or {name:spec} to format it with the printf
+// verb %spec instead of %v, e.g. {ratio:.2f}. Names are made of letters, digits
+// and underscores, a name can be used several times. Braces are escaped by
+// doubling them: {{ and }}.
+//
+// The template is translated to the printf template returned by MessageTemplate,
+// the args are the values of ArgNames in order, so the errors render like the
+// ones of printf templates and the positional methods like GenWithStackByArgs
+// can still be used.
+func NamedTemplate() NormalizeOption {
+	return func(e *Error) {
+		e.def.namedTemplate = e.message
+	}
+}
+
+// RedactNames returns a NormalizeOption to redact the named parameters with the
+// given names, like RedactArgs does by position. It must be used with NamedTemplate.
+func RedactNames(names ...string) NormalizeOption {
+	return func(e *Error) {
+		e.def.redactNames = append(e.def.redactNames, names...)
+	}
+}
+
+// Named returns the map of named args made of the given name and value pairs.
+// A trailing name without value is dropped, and reported as a problem unless
+// the template validation is off, see SetTemplateValidation.
+func Named(kv ...interface{}) map[string]interface{} {
+	named := make(map[string]interface{}, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		named[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	if len(kv)%2 != 0 && TemplateValidation(templateValidation.Load()) != TemplateValidationOff {
+		reportTemplateProblem(Errorf("invalid named args: %v has no value", kv[len(kv)-1]))
+	}
+	return named
+}
+
+// NamedTemplate returns the template given to Normalize with NamedTemplate, or
+// "" for printf templates.
+func (e *Error) NamedTemplate() string {
+	e.checkReleased()
+	return e.definition().namedTemplate
+}
+
+// ArgNames returns the names of the parameters of a named template, in the
+// order of the args of the printf template it is translated to.
+func (e *Error) ArgNames() []string {
+	e.checkReleased()
+	return append([]string(nil), e.definition().argNames...)
+}
+
+// GenWithStackByNamed is like GenWithStackByArgs, with the args given by name.
+// Missing args are rendered like fmt does, e.g. %!v(MISSING).
+func (e *Error) GenWithStackByNamed(named map[string]interface{}) error {
+	args := e.namedArgs(named)
+	RedactErrorArg(args, e.definition().redactArgsPos)
+	err := e.clone()
+	err.args = freezeHackedStringArgs(args)
+	err.fillLineAndFile(1)
+	return AddStack(err)
+}
+
+// FastGenByNamed is like FastGenByArgs, with the args given by name.
+func (e *Error) FastGenByNamed(named map[string]interface{}) error {
+	args := e.namedArgs(named)
+	RedactErrorArg(args, e.definition().redactArgsPos)
+	return SuspendStack(e.fastGen(args))
+}
+
+// missingArg formats a missing named arg like fmt formats missing args.
+type missingArg struct{}
+
+func (missingArg) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, "%%!%c(MISSING)", verb)
+}
+
+// namedArgs returns the positional args of the template of e from named ones.
+func (e *Error) namedArgs(named map[string]interface{}) []interface{} {
+	def := e.definition()
+	args := make([]interface{}, len(def.argNames))
+	var missing []string
+	for i, name := range def.argNames {
+		arg, ok := named[name]
+		if !ok {
+			arg = missingArg{}
+			missing = append(missing, name)
+		}
+		args[i] = arg
+	}
+	if TemplateValidation(templateValidation.Load()) != TemplateValidationOff {
+		var unknown []string
+		for name := range named {
+			if indexOf(def.argNames, name) < 0 {
+				unknown = append(unknown, name)
+			}
+		}
+		sort.Strings(unknown)
+		if len(missing) > 0 || len(unknown) > 0 {
+			reportTemplateProblem(Errorf("invalid args for error %s: template %q misses %v, does not take %v",
+				e.RFCCode(), def.namedTemplate, missing, unknown))
+		} else {
+			validateArgs(e, e.message, e.tmpl, args)
+		}
+	}
+	return args
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// compileNamedTemplate translates the named template of e, if it has one, to its
+// printf template and maps the names of RedactNames to positions. It returns the
+// problems found, if any.
+func compileNamedTemplate(e *Error) string {
+	def := e.def
+	if def.namedTemplate == "" {
+		if len(def.redactNames) > 0 {
+			return "RedactNames used without NamedTemplate"
+		}
+		return ""
+	}
+	type param struct{ name, spec string }
+	var (
+		params   []param
+		literals []string
+		lit      strings.Builder
+		problem  string
+	)
+	tmpl := def.namedTemplate
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		switch {
+		case c == '{' && i+1 < len(tmpl) && tmpl[i+1] == '{', c == '}' && i+1 < len(tmpl) && tmpl[i+1] == '}':
+			lit.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				problem = fmt.Sprintf("unterminated parameter in %q", tmpl)
+				lit.WriteString(escapePercent(tmpl[i:]))
+				i = len(tmpl)
+				continue
+			}
+			name, spec := tmpl[i+1:i+end], "v"
+			if colon := strings.IndexByte(name, ':'); colon >= 0 {
+				name, spec = name[:colon], name[colon+1:]
+			}
+			if !isParamName(name) || !isParamSpec(spec) {
+				problem = fmt.Sprintf("bad parameter %q", tmpl[i:i+end+1])
+				lit.WriteString(escapePercent(tmpl[i : i+end+1]))
+			} else {
+				literals = append(literals, lit.String())
+				lit.Reset()
+				params = append(params, param{name, spec})
+			}
+			i += end
+		case c == '%':
+			lit.WriteString("%%")
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	literals = append(literals, lit.String())
+
+	// the args are the distinct names in order of first use. Explicit arg
+	// indexes are only needed if a name is used twice.
+	def.argNames = def.argNames[:0]
+	indexed := false
+	for _, p := range params {
+		if indexOf(def.argNames, p.name) >= 0 {
+			indexed = true
+		} else {
+			def.argNames = append(def.argNames, p.name)
+		}
+	}
+	var b strings.Builder
+	for i, p := range params {
+		b.WriteString(literals[i])
+		b.WriteByte('%')
+		if indexed {
+			// the index goes right before the verb, after the flags, width and precision.
+			_, size := utf8.DecodeLastRuneInString(p.spec)
+			b.WriteString(p.spec[:len(p.spec)-size])
+			b.WriteString("[" + strconv.Itoa(indexOf(def.argNames, p.name)+1) + "]")
+			b.WriteString(p.spec[len(p.spec)-size:])
+		} else {
+			b.WriteString(p.spec)
+		}
+	}
+	b.WriteString(literals[len(literals)-1])
+	e.message = b.String()
+
+	for _, name := range def.redactNames {
+		pos := indexOf(def.argNames, name)
+		if pos < 0 {
+			problem = fmt.Sprintf("RedactNames: no parameter %q in %q", name, tmpl)
+			continue
+		}
+		def.redactArgsPos = append(def.redactArgsPos[:len(def.redactArgsPos):len(def.redactArgsPos)], pos)
+	}
+	return problem
+}
+
+// escapePercent escapes the literal text s of a named template for the printf
+// template it is translated to.
+func escapePercent(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+func isParamName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isParamSpec reports whether spec is a printf verb, with flags, width and
+// precision but without arg index nor '*'.
+func isParamSpec(spec string) bool {
+	if spec == "" || strings.ContainsAny(spec, "[*%") {
+		return false
+	}
+	t := parseTemplate("%" + spec)
+	return t.problem == "" && len(t.verbs) == 1 && t.literals[0] == "" && t.literals[1] == ""
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamedTemplate(t *testing.T) {
+	errDup := Normalize("Duplicate entry '{entry}' for key '{key}' (100%)",
+		RFCCodeText("test-named:Dup"), NamedTemplate(), RedactNames("entry"))
+	require.Equal(t, "Duplicate entry '%v' for key '%v' (100%%)", errDup.MessageTemplate())
+	require.Equal(t, "Duplicate entry '{entry}' for key '{key}' (100%)", errDup.NamedTemplate())
+	require.Equal(t, []string{"entry", "key"}, errDup.ArgNames())
+
+	err := errDup.GenWithStackByNamed(Named("key", "PRIMARY", "entry", "1"))
+	require.True(t, HasStack(err))
+	require.Equal(t, "[test-named:Dup]Duplicate entry '1' for key 'PRIMARY' (100%)", err.Error())
+	require.Equal(t, err.Error(), errDup.GenWithStackByArgs("1", "PRIMARY").Error())
+
+	RedactLogEnabled.Store(RedactLogEnable)
+	err = errDup.FastGenByNamed(map[string]interface{}{"entry": "secret", "key": "PRIMARY"})
+	RedactLogEnabled.Store(RedactLogDisable)
+	require.False(t, HasStack(err))
+	require.Equal(t, "[test-named:Dup]Duplicate entry '?' for key 'PRIMARY' (100%)", err.Error())
+
+	err = errDup.FastGenByNamed(Named("entry", "1"))
+	require.Equal(t, "[test-named:Dup]Duplicate entry '1' for key '%!v(MISSING)' (100%)", err.Error())
+}
+
+func TestNamedTemplateSyntax(t *testing.T) {
+	cases := []struct {
+		tmpl, printf string
+		names        []string
+		named        map[string]interface{}
+		msg          string
+	}{
+		{"{a} and {b:d}", "%v and %d", []string{"a", "b"}, Named("a", "x", "b", 2), "x and 2"},
+		{"{a}={b:.2f}, again {a:q}", "%[1]v=%.2[2]f, again %[1]q", []string{"a", "b"}, Named("a", "r", "b", 0.5), `r=0.50, again "r"`},
+		{"{{literal}} {x}}}", "{literal} %v}", []string{"x"}, Named("x", 1), "{literal} 1}"},
+		{"no params", "no params", nil, nil, "no params"},
+	}
+	for _, c := range cases {
+		e := Normalize(c.tmpl, NamedTemplate())
+		require.Equal(t, c.printf, e.MessageTemplate(), c.tmpl)
+		require.Equal(t, c.names, e.ArgNames(), c.tmpl)
+		require.Equal(t, c.msg, e.FastGenByNamed(c.named).(*Error).GetMsg(), c.tmpl)
+	}
+}
+
+func TestNamedTemplateValidation(t *testing.T) {
+	var problems []error
+	SetTemplateValidationHook(func(err error) { problems = append(problems, err) })
+	SetTemplateValidation(TemplateValidationHook)
+	defer func() {
+		SetTemplateValidation(TemplateValidationOff)
+		SetTemplateValidationHook(nil)
+	}()
+
+	errRows := Normalize("table {table} has {rows:d} rows", NamedTemplate(), RFCCodeText("test-named:Rows"))
+	_ = errRows.FastGenByNamed(Named("table", "t", "rows", 1))
+	require.Empty(t, problems)
+
+	_ = errRows.FastGenByNamed(Named("table", "t", "count", 1))
+	_ = errRows.FastGenByNamed(Named("table", "t", "rows", "1"))
+	_ = Normalize("bad {param", NamedTemplate())
+	_ = Normalize("bad {pa-ram}", NamedTemplate())
+	_ = Normalize("bad {param:[1]d}", NamedTemplate())
+	_ = Normalize("{param}", NamedTemplate(), RedactNames("other"))
+	_ = Normalize("%s", RedactNames("other"))
+	require.Len(t, problems, 7)
+	require.Contains(t, problems[0].Error(), "misses [rows], does not take [count]")
+	require.Contains(t, problems[1].Error(), "can not format arg 1 of type string with %d")
+	require.Contains(t, problems[2].Error(), "unterminated parameter")
+	require.Contains(t, problems[3].Error(), `bad parameter "{pa-ram}"`)
+	require.Contains(t, problems[4].Error(), `bad parameter "{param:[1]d}"`)
+	require.Contains(t, problems[5].Error(), `RedactNames: no parameter "other"`)
+	require.Contains(t, problems[6].Error(), "RedactNames used without NamedTemplate")
+}
+
+func TestNamedTemplateBadParamsEscaped(t *testing.T) {
+	for tmpl, msg := range map[string]string{
+		"{x} {50%":           "1 {50%",
+		"{50%} and {x}":      "{50%} and 1",
+		"{x} is {100%:bad}%": "1 is {100%:bad}%",
+	} {
+		e := Normalize(tmpl, NamedTemplate())
+		require.Equal(t, msg, e.FastGenByNamed(Named("x", 1)).(*Error).GetMsg(), tmpl)
+		require.Equal(t, msg, e.FastGenByArgs(1).(*Error).GetMsg(), tmpl)
+	}
+}
+
+func TestNamedOddArgs(t *testing.T) {
+	var problems []error
+	SetTemplateValidationHook(func(err error) { problems = append(problems, err) })
+	SetTemplateValidation(TemplateValidationHook)
+	defer func() {
+		SetTemplateValidation(TemplateValidationOff)
+		SetTemplateValidationHook(nil)
+	}()
+
+	require.Equal(t, map[string]interface{}{"a": 1}, Named("a", 1, "b"))
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0].Error(), "b has no value")
+
+	SetTemplateValidation(TemplateValidationOff)
+	require.Equal(t, map[string]interface{}{"a": 1}, Named("a", 1, "b"))
+	require.Len(t, problems, 1)
+}
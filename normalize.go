@@ -15,8 +15,11 @@ package errors
 
 import (
 	"fmt"
+	"io"
 	"runtime"
 	"strconv"
+	"sync"
+	"unsafe"
 
 	"go.uber.org/atomic"
 )
@@ -78,17 +81,68 @@ type Error struct {
 	// message is a template of the description of this error.
 	// printf-style formatting is enabled.
 	message string
+	// tmpl is message parsed by Normalize. It is nil once message is replaced.
+	tmpl *parsedTemplate
+	// def is the definition given to Normalize, shared by the errors generated
+	// from the prototype. It is nil for the errors not created by Normalize.
+	def *errorDef
+	// Cause is used to warp some third party error.
+	cause error
+	args  []interface{}
+	file  string
+	line  int
+	// rendered is set on the errors decoded by UnmarshalJSON, whose text is set
+	// to the message rendered by the sender. The decoded args are strings, so
+	// the message can not always be rendered again from them.
+	rendered bool
+	// text caches the *renderedText of the error, see cachedText.
+	text atomic.UnsafePointer
+	// pooled is set on the errors taken from a pool, see Release.
+	pooled *fastError
+}
+
+// errorDef is the definition of a prototype set by the options of Normalize.
+// It is not modified once Normalize returns.
+type errorDef struct {
 	// redactArgsPos defines the positions of arguments in message that need to be redacted.
 	// And it is controlled by the global var RedactLogEnabled.
 	// For example, an original error is `Duplicate entry 'PRIMARY' for key 'key'`,
 	// when RedactLogEnabled is ON and redactArgsPos is [0, 1], the error is `Duplicate entry '?' for key '?'`.
 	// when RedactLogEnabled is MARKER and redactArgsPos is [0, 1], the error is `Duplicate entry '‹..›' for key '‹..›'`.
 	redactArgsPos []int
-	// Cause is used to warp some third party error.
-	cause error
-	args  []interface{}
-	file  string
-	line  int
+	// matchAnyLayer makes Equal match any *Error layer of the chain instead of
+	// only the root cause, see MatchAnyLayer.
+	matchAnyLayer bool
+	severity      Severity
+	category      string
+	// previousCodeTexts and previousCodes are the codes the error was known by
+	// in earlier releases, see PreviousRFCCodes.
+	previousCodeTexts []ErrCodeText
+	previousCodes     []ErrCode
+	// sqlState is the SQLSTATE set by the SQLState option.
+	sqlState string
+	// namedTemplate is the template given to Normalize with NamedTemplate,
+	// translated to message. argNames are the names of its args.
+	namedTemplate string
+	argNames      []string
+	redactNames   []string
+	// pool is the pool of the errors generated by the FastGen* methods, see Pooled.
+	pool *sync.Pool
+	// prototype is the error returned by Normalize, and stackless caches the
+	// *Error returned by its FastGenByArgs without args.
+	prototype *Error
+	stackless atomic.UnsafePointer
+}
+
+// noDef is the definition of the errors not created by Normalize.
+var noDef errorDef
+
+// definition returns the definition of e, which must not be modified.
+func (e *Error) definition() *errorDef {
+	if e.def == nil {
+		return &noDef
+	}
+	return e.def
 }
 
 var _ messenger = (*Error)(nil)
@@ -99,6 +153,7 @@ var _ fmt.Formatter = (*Error)(nil)
 // when you just want to get the purely numeric error
 // (e.g., for mysql protocol transmission.), this would be useful.
 func (e *Error) Code() ErrCode {
+	e.checkReleased()
 	return e.code
 }
 
@@ -106,12 +161,14 @@ func (e *Error) Code() ErrCode {
 //
 // The error code is a 3-tuple of abbreviated component name, error class and error code,
 // joined by a colon like {Component}:{ErrorClass}:{InnerErrorCode}.
+// Use ParsedRFCCode to get its parts.
 func (e *Error) RFCCode() RFCErrorCode {
 	return RFCErrorCode(e.ID())
 }
 
 // ID returns the ID of this error.
 func (e *Error) ID() ErrorID {
+	e.checkReleased()
 	if e.codeText != "" {
 		return ErrorID(e.codeText)
 	}
@@ -121,16 +178,19 @@ func (e *Error) ID() ErrorID {
 // Location returns the location where the error is created,
 // implements juju/errors locationer interface.
 func (e *Error) Location() (file string, line int) {
+	e.checkReleased()
 	return e.file, e.line
 }
 
 // MessageTemplate returns the error message template of this error.
 func (e *Error) MessageTemplate() string {
+	e.checkReleased()
 	return e.message
 }
 
 // Args returns the message arguments of this error.
 func (e *Error) Args() []interface{} {
+	e.checkReleased()
 	return e.args
 }
 
@@ -140,33 +200,69 @@ func (e *Error) Error() string {
 		return "<nil>"
 	}
 	if e.cause != nil {
-		return fmt.Sprintf("[%s]%s: %s", e.RFCCode(), e.GetMsg(), e.cause.Error())
+		return e.cachedText().text + ": " + e.cause.Error()
 	}
-	return fmt.Sprintf("[%s]%s", e.RFCCode(), e.GetMsg())
+	return e.cachedText().text
 }
 
 func (e *Error) Format(s fmt.State, verb rune) {
-	switch verb {
-	case 'v':
-		if s.Flag('+') {
-			if e != nil && e.cause != nil {
-				fmt.Fprintf(s, "%+v\n", e.cause)
-				fmt.Fprintf(s, "[%s]%s", e.RFCCode(), e.GetMsg())
-				return
-			}
-			fmt.Fprint(s, e.Error())
-			return
-		}
-		fallthrough
-	case 's':
-		fmt.Fprint(s, e.Error())
-	case 'q':
-		fmt.Fprintf(s, "%q", e.Error())
+	if e == nil {
+		fmt.Fprintf(s, fmt.FormatString(s, 's'), e.Error())
+		return
 	}
+	e.checkReleased()
+	formatError(s, verb, e)
+}
+
+func (e *Error) formatExtended(s fmt.State) {
+	if e.cause != nil {
+		writeExtended(s, e.cause)
+		io.WriteString(s, "\n")
+		writeMessage(s, e.cachedText().text)
+		return
+	}
+	writeMessage(s, e.Error())
 }
 
 func (e *Error) GetMsg() string {
+	e.checkReleased()
+	return e.cachedText().msg
+}
+
+// renderedText is the message of an *Error, and its text returned by Error
+// when it has no cause.
+type renderedText struct {
+	msg  string
+	text string
+}
+
+// cachedText returns the rendered message of e, rendering it on first use only.
+// The message of an error never changes: its args are immutable once generated,
+// HackedStr ones being frozen, and they are redacted by the Gen* methods
+// according to RedactLogEnabled at that time. Concurrent first uses may render
+// it several times, all of them storing the same message.
+func (e *Error) cachedText() *renderedText {
+	e.checkReleased()
+	if t := (*renderedText)(e.text.Load()); t != nil {
+		return t
+	}
+	return e.setText(e.renderMsg())
+}
+
+// setText sets the cached message of e to msg.
+func (e *Error) setText(msg string) *renderedText {
+	t := &renderedText{msg: msg, text: "[" + string(e.RFCCode()) + "]" + msg}
+	e.text.Store(unsafe.Pointer(t))
+	return t
+}
+
+func (e *Error) renderMsg() string {
 	if len(e.args) > 0 {
+		if e.tmpl != nil {
+			if msg, ok := e.tmpl.render(e.args); ok {
+				return msg
+			}
+		}
 		return fmt.Sprintf(e.message, e.args...)
 	}
 	return e.message
@@ -190,7 +286,97 @@ func freezeHackedStringArgs(args []interface{}) []interface{} {
 	return args
 }
 
+// clone returns a copy of e for the Gen* methods, which replace its message or
+// args. The message rendered from them is not copied.
+func (e *Error) clone() *Error {
+	return e.cloneTo(&Error{})
+}
+
+// cloneTo makes err a copy of e like clone, and returns it.
+func (e *Error) cloneTo(err *Error) *Error {
+	e.checkReleased()
+	*err = Error{
+		code:     e.code,
+		codeText: e.codeText,
+		message:  e.message,
+		tmpl:     e.tmpl,
+		def:      e.def,
+		cause:    e.cause,
+		args:     e.args,
+		file:     e.file,
+		line:     e.line,
+	}
+	return err
+}
+
+// fastErrorArgs is the number of args an *Error generated by the FastGen*
+// methods holds without allocating their backing array.
+const fastErrorArgs = 2
+
+// fastError is an *Error allocated along with the backing array of its args.
+type fastError struct {
+	// poolState is the state of pooled errors checked in debug builds, see
+	// checkReleased. It is empty in the other builds.
+	poolState
+	Error
+	args [fastErrorArgs]interface{}
+}
+
+// fastGen returns a copy of e with a copy of args, for the FastGen* methods.
+// It allocates once if there are no more than fastErrorArgs args, or not at all
+// if e is pooled, and does not retain args so that the callers' variadic args
+// need not be allocated.
+func (e *Error) fastGen(args []interface{}) *Error {
+	pool := e.definition().pool
+	var f *fastError
+	if pool != nil {
+		f = pool.Get().(*fastError)
+	} else {
+		f = &fastError{}
+	}
+	err := e.cloneTo(&f.Error)
+	if pool != nil {
+		err.pooled = f
+	}
+	switch {
+	case len(args) == 0:
+		err.args = nil
+	case len(args) <= fastErrorArgs:
+		err.args = f.args[:len(args):len(args)]
+	default:
+		err.args = make([]interface{}, len(args))
+	}
+	copy(err.args, args)
+	freezeHackedStringArgs(err.args)
+	return err
+}
+
+// stacklessInstance returns the *Error returned by FastGenByArgs without args,
+// or nil if e is not a prototype returned by Normalize. It is created on first
+// use only: errors without args are not modified by this package, so the same
+// instance is returned each time.
+func (e *Error) stacklessInstance() *Error {
+	if e.def == nil || e.def.prototype != e {
+		return nil
+	}
+	if err := (*Error)(e.def.stackless.Load()); err != nil {
+		return err
+	}
+	err := e.fastGen(nil)
+	// the instance is shared, it must never be released.
+	err.pooled = nil
+	e.def.stackless.Store(unsafe.Pointer(err))
+	return err
+}
+
+// isStacklessInstance reports whether e is the instance shared by the
+// FastGenByArgs calls without args, see stacklessInstance.
+func (e *Error) isStacklessInstance() bool {
+	return e.def != nil && (*Error)(e.def.stackless.Load()) == e
+}
+
 func (e *Error) GetSelfMsg() string {
+	e.checkReleased()
 	return e.GetMsg()
 }
 
@@ -209,44 +395,83 @@ func (e *Error) fillLineAndFile(skip int) {
 // GenWithStack generates a new *Error with the same class and code, and a new formatted message.
 func (e *Error) GenWithStack(format string, args ...interface{}) error {
 	// TODO: RedactErrorArg
-	err := *e
+	validateArgs(e, format, nil, args)
+	err := e.clone()
 	err.message = format
+	err.tmpl = nil
 	err.args = freezeHackedStringArgs(args)
 	err.fillLineAndFile(1)
-	return AddStack(&err)
+	return AddStack(err)
 }
 
 // GenWithStackByArgs generates a new *Error with the same class and code, and new arguments.
 func (e *Error) GenWithStackByArgs(args ...interface{}) error {
-	RedactErrorArg(args, e.redactArgsPos)
-	err := *e
+	validateArgs(e, e.message, e.tmpl, args)
+	RedactErrorArg(args, e.definition().redactArgsPos)
+	err := e.clone()
 	err.args = freezeHackedStringArgs(args)
 	err.fillLineAndFile(1)
-	return AddStack(&err)
+	return AddStack(err)
 }
 
 // FastGen generates a new *Error with the same class and code, and a new formatted message.
 // This will not call runtime.Caller to get file and line.
 func (e *Error) FastGen(format string, args ...interface{}) error {
 	// TODO: RedactErrorArg
-	err := *e
+	validateArgs(e, format, nil, args)
+	err := e.fastGen(args)
 	err.message = format
-	err.args = freezeHackedStringArgs(args)
-	return SuspendStack(&err)
+	err.tmpl = nil
+	return SuspendStack(err)
 }
 
 // FastGen generates a new *Error with the same class and code, and a new arguments.
 // This will not call runtime.Caller to get file and line.
+//
+// The *Error is returned without stack, so HasStack reports false and Trace can
+// add one later. It allocates at most once with up to 2 args, the *Error along
+// with its args, and twice with more args, whose copy is allocated apart.
+// Without args, the prototypes returned by Normalize return the same instance
+// each time and allocate nothing: that instance is shared, UnmarshalJSON refuses
+// to decode into it.
 func (e *Error) FastGenByArgs(args ...interface{}) error {
-	RedactErrorArg(args, e.redactArgsPos)
-	err := *e
+	validateArgs(e, e.message, e.tmpl, args)
+	if len(args) == 0 && e.cause == nil {
+		if err := e.stacklessInstance(); err != nil {
+			return err
+		}
+	}
+	RedactErrorArg(args, e.definition().redactArgsPos)
+	return SuspendStack(e.fastGen(args))
+}
+
+// SampledGenByArgs generates a new *Error with the same class and code, and new arguments.
+// Whether the stack trace is captured is decided by the StackSampler set for the RFC code
+// of e, or the default one. An error sampled out behaves like FastGenByArgs: HasStack
+// reports false and Trace can still add a stack later.
+func (e *Error) SampledGenByArgs(args ...interface{}) error {
+	validateArgs(e, e.message, e.tmpl, args)
+	RedactErrorArg(args, e.definition().redactArgsPos)
+	err := e.clone()
 	err.args = freezeHackedStringArgs(args)
-	return SuspendStack(&err)
+	if !shouldSampleStack(e.RFCCode()) {
+		return SuspendStack(err)
+	}
+	err.fillLineAndFile(1)
+	return AddStack(err)
 }
 
 // Equal checks if err is equal to e.
+// By default only RootCause(err) is compared, so an *Error wrapped by
+// another one via Wrap never matches. Prototypes normalized with MatchAnyLayer
+// match if any *Error layer of err has the same ID, like ContainsCode.
+// The previous codes of both errors, see PreviousRFCCodes, are taken into account.
 func (e *Error) Equal(err error) bool {
-	originErr := Cause(err)
+	e.checkReleased()
+	if e.definition().matchAnyLayer && ContainsCode(err, e) {
+		return true
+	}
+	originErr := RootCause(err)
 	if originErr == nil {
 		return false
 	}
@@ -257,8 +482,7 @@ func (e *Error) Equal(err error) bool {
 	if !ok {
 		return false
 	}
-	idEquals := e.ID() == inErr.ID()
-	return idEquals
+	return e.sameKind(inErr)
 }
 
 // NotEqual checks if err is not equal to e.
@@ -285,9 +509,11 @@ func RedactErrorArg(args []interface{}, position []int) {
 }
 
 // ErrorEqual returns a boolean indicating whether err1 is equal to err2.
+// The root causes, see RootCause, are compared: by ID if both are *Error,
+// by message otherwise.
 func ErrorEqual(err1, err2 error) bool {
-	e1 := Cause(err1)
-	e2 := Cause(err2)
+	e1 := RootCause(err1)
+	e2 := RootCause(err2)
 
 	if e1 == e2 {
 		return true
@@ -317,13 +543,21 @@ type jsonError struct {
 	Code    int    `json:"code"`
 	Msg     string `json:"message"`
 	RFCCode string `json:"rfccode"`
+	// Template, Args and Cause are absent from the payloads of older versions.
+	Template string     `json:"template,omitempty"`
+	Args     []string   `json:"args,omitempty"`
+	Cause    *jsonError `json:"cause,omitempty"`
 }
 
 func (e *Error) Wrap(err error) *Error {
 	if err != nil {
-		newErr := *e
+		newErr := e.clone()
+		newErr.rendered = e.rendered
+		if e.rendered {
+			newErr.text.Store(e.text.Load())
+		}
 		newErr.cause = err
-		return &newErr
+		return newErr
 	}
 	return nil
 }
@@ -335,20 +569,27 @@ func (e *Error) Unwrap() error {
 	if e == nil {
 		return nil
 	}
+	e.checkReleased()
 	return e.cause
 }
 
-// Is checks if e has the same error ID with other.
+// Is checks if e has the same error ID with other, or is known by one of its
+// previous codes, see PreviousRFCCodes.
 // It allows Error to work with errors.Is() from the Go standard package.
 func (e *Error) Is(other error) bool {
+	e.checkReleased()
 	err, ok := other.(*Error)
 	if !ok {
 		return false
 	}
-	return (e == nil && err == nil) || (e != nil && err != nil && e.ID() == err.ID())
+	return (e == nil && err == nil) || (e != nil && err != nil && e.sameKind(err))
 }
 
+// Cause returns the cause of the error wrapped by e, or the wrapped error itself
+// if it has no cause, skipping one layer. It is kept for compatibility, use
+// DirectCause(e) for the wrapped error or RootCause(e) for the innermost one.
 func (e *Error) Cause() error {
+	e.checkReleased()
 	root := Unwrap(e.cause)
 	if root == nil {
 		return e.cause
@@ -357,29 +598,30 @@ func (e *Error) Cause() error {
 }
 
 func (e *Error) FastGenWithCause(args ...interface{}) error {
-	err := *e
+	err := e.fastGen(args)
 	if e.cause != nil {
 		err.message = e.cause.Error()
+		err.tmpl = nil
 	}
-	err.args = freezeHackedStringArgs(args)
-	return SuspendStack(&err)
+	return SuspendStack(err)
 }
 
 func (e *Error) GenWithStackByCause(args ...interface{}) error {
-	err := *e
+	err := e.clone()
 	if e.cause != nil {
 		err.message = e.cause.Error()
+		err.tmpl = nil
 	}
 	err.args = freezeHackedStringArgs(args)
 	err.fillLineAndFile(1)
-	return AddStack(&err)
+	return AddStack(err)
 }
 
 type NormalizeOption func(*Error)
 
 func RedactArgs(pos []int) NormalizeOption {
 	return func(e *Error) {
-		e.redactArgsPos = pos
+		e.def.redactArgsPos = pos
 	}
 }
 
@@ -390,6 +632,15 @@ func RFCCodeText(codeText string) NormalizeOption {
 	}
 }
 
+// MatchAnyLayer returns a NormalizeOption making Equal match an error if any
+// *Error layer of its chain has the same ID as the prototype, not only its root cause.
+// The errors generated from the prototype inherit the option.
+func MatchAnyLayer() NormalizeOption {
+	return func(e *Error) {
+		e.def.matchAnyLayer = true
+	}
+}
+
 // MySQLErrorCode returns a NormalizeOption to set error code.
 func MySQLErrorCode(code int) NormalizeOption {
 	return func(e *Error) {
@@ -398,13 +649,22 @@ func MySQLErrorCode(code int) NormalizeOption {
 }
 
 // Normalize creates a new Error object.
+// The RFC code set by RFCCodeText is validated according to SetRFCCodeValidation.
 func Normalize(message string, opts ...NormalizeOption) *Error {
 	e := &Error{
 		message: message,
+		def:     &errorDef{},
 	}
+	e.def.prototype = e
 	for _, opt := range opts {
 		opt(e)
 	}
+	problem := compileNamedTemplate(e)
+	e.tmpl = parseTemplate(e.message)
+	validateNormalized(e)
+	validateTemplate(e, problem)
+	registerAliases(e)
+	registerPrototypeArgs(e)
 	return e
 }
 
@@ -1,10 +1,12 @@
 package errors
 
 import (
+	"encoding/json"
 	stderrors "errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"unsafe"
 )
@@ -132,7 +134,7 @@ func TestFastGenByArgsFreezeHackedStringArg(t *testing.T) {
 	err := errTest.FastGenByArgs(arg)
 
 	copy(origin, "1 1:1:1.0000027")
-	got := err.(*withStack).error.(*Error).GetMsg()
+	got := err.(*Error).GetMsg()
 	want := "Incorrect time value: '120120519090607'"
 	if got != want {
 		t.Fatalf("message changed after source bytes mutated, got %q, want %q", got, want)
@@ -162,7 +164,7 @@ func TestFastGenFreezeHackedStringArg(t *testing.T) {
 	err := errTest.FastGen("Incorrect time value: '%s'", arg)
 
 	copy(origin, "1 1:1:1.0000027")
-	got := err.(*withStack).error.(*Error).GetMsg()
+	got := err.(*Error).GetMsg()
 	want := "Incorrect time value: '120120519090607'"
 	if got != want {
 		t.Fatalf("message changed after source bytes mutated, got %q, want %q", got, want)
@@ -192,9 +194,75 @@ func TestFastGenWithCauseFreezeHackedStringArg(t *testing.T) {
 	err := errTest.FastGenWithCause(arg)
 
 	copy(origin, "1 1:1:1.0000027")
-	got := err.(*withStack).error.(*Error).GetMsg()
+	got := err.(*Error).GetMsg()
 	want := "Incorrect time value: '120120519090607'"
 	if got != want {
 		t.Fatalf("message changed after source bytes mutated, got %q, want %q", got, want)
 	}
 }
+
+func TestErrorTextCache(t *testing.T) {
+	errTest := Normalize("Duplicate entry '%s' for key '%s'", RFCCodeText("Internal:TextCache"), RedactArgs([]int{0}))
+	if got, want := errTest.Error(), "[Internal:TextCache]Duplicate entry '%s' for key '%s'"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// the text of the prototype is not copied by the Gen* methods.
+	err := errTest.FastGenByArgs("1", "PRIMARY").(*Error)
+	want := "[Internal:TextCache]Duplicate entry '1' for key 'PRIMARY'"
+	for i := 0; i < 2; i++ {
+		if got := err.Error(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	if got := err.GetMsg(); got != "Duplicate entry '1' for key 'PRIMARY'" {
+		t.Fatalf("got %q", got)
+	}
+	if got, want := err.Wrap(stderrors.New("cause")).Error(), want+": cause"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// errors generated after RedactLogEnabled changes are redacted, the
+	// message of the ones generated before does not change.
+	RedactLogEnabled.Store(RedactLogEnable)
+	redacted := errTest.FastGenByArgs("1", "PRIMARY")
+	RedactLogEnabled.Store(RedactLogDisable)
+	if got := redacted.Error(); got != "[Internal:TextCache]Duplicate entry '?' for key 'PRIMARY'" {
+		t.Fatalf("got %q", got)
+	}
+	if got := err.Error(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// decoding into an error replaces its text.
+	data, jsonErr := json.Marshal(errTest.FastGenByArgs("2", "PRIMARY"))
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if jsonErr := json.Unmarshal(data, err); jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if got := err.Error(); got != "[Internal:TextCache]Duplicate entry '2' for key 'PRIMARY'" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestErrorTextCacheConcurrent(t *testing.T) {
+	errTest := Normalize("table %s has %d rows", RFCCodeText("Internal:TextCache"))
+	err := errTest.FastGenByArgs("t1", 42)
+	want := "[Internal:TextCache]table t1 has 42 rows"
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := err.Error(); got != want {
+					t.Errorf("got %q, want %q", got, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedError is one layer of an error reconstructed from its %+v output by
+// ParseErrorStack. The layers are linked from the outermost one to the root
+// cause, the members of a Join are the children of a layer of their own.
+type ParsedError struct {
+	// Message is the message of this layer, without the RFC code. It is empty
+	// for the layers printed by WithStack and Join.
+	Message string
+	// Code is the RFC code of a "[code]message" line printed by *Error.
+	Code RFCErrorCode
+	// Stack holds the frames printed after the message, innermost first.
+	Stack []FrameInfo
+	// CreatedAt and GoroutineID are set if the stack metadata was printed,
+	// see EnableStackMetadata.
+	CreatedAt   time.Time
+	GoroutineID int64
+	// Cause is the layer printed before this one, nil for the root cause.
+	Cause *ParsedError
+	// Errors are the members of a Join, for the layer printed by it. Such a
+	// layer has no cause.
+	Errors []*ParsedError
+}
+
+// RootCause returns the innermost layer of the chain, which is the layer of a
+// Join if the chain wraps one.
+func (p *ParsedError) RootCause() *ParsedError {
+	for p.Cause != nil {
+		p = p.Cause
+	}
+	return p
+}
+
+// Layers returns all layers from the outermost one to the root cause.
+func (p *ParsedError) Layers() []*ParsedError {
+	var layers []*ParsedError
+	for ; p != nil; p = p.Cause {
+		layers = append(layers, p)
+	}
+	return layers
+}
+
+// ParseErrorStack parses the output of ErrorStack, that is fmt.Sprintf("%+v", err),
+// for the errors of this package back into a structured form.
+//
+// Every message line starts a new layer, whose stack is the one printed right after
+// it. A stack trace printed right after another one, as for WithStack(WithStack(err)),
+// is a layer of its own without message: a stack trace ends with the runtime.goexit
+// frame of its goroutine, or after the 32 frames recorded at most. The members of a
+// Join, each printed on lines starting with "- ", are parsed as the Errors of a layer
+// of their own.
+//
+// Foreign errors printing more than their message for %+v, like the ones of
+// github.com/pkg/errors, may print lines that are parsed as layers of their own.
+func ParseErrorStack(dump string) (*ParsedError, error) {
+	if dump == "" {
+		return nil, New("empty error stack")
+	}
+	return parseLayers(strings.Split(dump, "\n"), 1)
+}
+
+// parseLayers parses the lines of the %+v output of an error, starting at line
+// first of the dump.
+func parseLayers(lines []string, first int) (*ParsedError, error) {
+	var top *ParsedError
+	// inMessage is true while the lines of the message of top may go on.
+	inMessage := false
+	for i := 0; i < len(lines); i++ {
+		line, n := lines[i], first+i
+		switch {
+		case strings.HasPrefix(line, joinMemberPrefix):
+			if top != nil {
+				return nil, Errorf("line %d: joined errors printed after another error", n)
+			}
+			top = &ParsedError{}
+			for i < len(lines) && strings.HasPrefix(lines[i], joinMemberPrefix) {
+				member := []string{lines[i][len(joinMemberPrefix):]}
+				j := i + 1
+				for ; j < len(lines) && strings.HasPrefix(lines[j], continuationIndent); j++ {
+					member = append(member, lines[j][len(continuationIndent):])
+				}
+				p, err := parseLayers(member, first+i)
+				if err != nil {
+					return nil, err
+				}
+				top.Errors = append(top.Errors, p)
+				i = j
+			}
+			i--
+			inMessage = false
+		case strings.HasPrefix(line, continuationIndent):
+			if !inMessage {
+				return nil, Errorf("line %d: indented line without message", n)
+			}
+			top.Message += "\n" + line[len(continuationIndent):]
+		case i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t"):
+			frame, err := parseFrame(line, lines[i+1][1:])
+			if err != nil {
+				return nil, Annotatef(err, "line %d", n+1)
+			}
+			if top == nil {
+				return nil, Errorf("line %d: stack frame without message", n)
+			}
+			if len(top.Errors) > 0 || stackEnded(top.Stack) {
+				top = &ParsedError{Cause: top}
+			}
+			top.Stack = append(top.Stack, frame)
+			i++
+			inMessage = false
+		case strings.HasPrefix(line, "\t"):
+			return nil, Errorf("line %d: file position without function", n)
+		case isStackMetaLine(line):
+			if top == nil {
+				return nil, Errorf("line %d: stack metadata without message", n)
+			}
+			// the metadata is printed before the stack it belongs to.
+			if len(top.Errors) > 0 || len(top.Stack) > 0 {
+				top = &ParsedError{Cause: top}
+			}
+			if err := parseStackMeta(line, top); err != nil {
+				return nil, Annotatef(err, "line %d", n)
+			}
+			inMessage = false
+		default:
+			code, msg := splitRFCCode(line)
+			top = &ParsedError{Message: msg, Code: code, Cause: top}
+			inMessage = true
+		}
+	}
+	return top, nil
+}
+
+// stackEnded tells whether a stack trace printed right after the given frames is
+// another one.
+func stackEnded(frames []FrameInfo) bool {
+	return len(frames) >= maxStackDepth || len(frames) > 0 && frames[len(frames)-1].Func == "runtime.goexit"
+}
+
+// parseFrame parses the two lines printed by Frame for %+v: "<funcname>" and "\t<file>:<line>".
+func parseFrame(fn, pos string) (FrameInfo, error) {
+	i := strings.LastIndex(pos, ":")
+	if i < 0 {
+		return FrameInfo{}, Errorf("invalid file position %q", pos)
+	}
+	line, err := strconv.Atoi(pos[i+1:])
+	if err != nil {
+		return FrameInfo{}, Errorf("invalid line number in %q", pos)
+	}
+	return FrameInfo{
+		Func:    fn,
+		Package: pkgname(fn),
+		File:    pos[:i],
+		Line:    line,
+	}, nil
+}
+
+func isStackMetaLine(line string) bool {
+	return strings.HasPrefix(line, "(goroutine ") && strings.HasSuffix(line, ")")
+}
+
+func parseStackMeta(line string, p *ParsedError) error {
+	body := strings.TrimSuffix(strings.TrimPrefix(line, "(goroutine "), ")")
+	parts := strings.SplitN(body, ", created at ", 2)
+	if len(parts) != 2 {
+		return Errorf("invalid stack metadata %q", line)
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return Annotatef(err, "invalid goroutine id in %q", line)
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return Annotatef(err, "invalid creation time in %q", line)
+	}
+	p.GoroutineID, p.CreatedAt = id, t
+	return nil
+}
+
+// splitRFCCode splits a line printed by *Error like "[code]message".
+func splitRFCCode(line string) (RFCErrorCode, string) {
+	if !strings.HasPrefix(line, "[") {
+		return "", line
+	}
+	end := strings.Index(line, "]")
+	if end < 2 || strings.ContainsAny(line[1:end], " \t[") {
+		return "", line
+	}
+	return RFCErrorCode(line[1:end]), line[end+1:]
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// parsedBlocks returns the blocks of the %+v output p was parsed from, as split by
+// parseBlocks in format_test.go: one per line of message and one per stack trace.
+func parsedBlocks(p *ParsedError) []string {
+	var blocks []string
+	if p.Cause != nil {
+		blocks = parsedBlocks(p.Cause)
+	}
+	for _, member := range p.Errors {
+		for i, b := range parsedBlocks(member) {
+			prefix := continuationIndent
+			if i == 0 {
+				prefix = joinMemberPrefix
+			}
+			blocks = append(blocks, prefix+strings.ReplaceAll(b, "\n", "\n"+continuationIndent))
+		}
+	}
+	if p.Message != "" || p.Code != "" {
+		msg := p.Message
+		if p.Code != "" {
+			msg = "[" + string(p.Code) + "]" + msg
+		}
+		lines := strings.Split(msg, "\n")
+		blocks = append(blocks, lines[0])
+		for _, line := range lines[1:] {
+			blocks = append(blocks, continuationIndent+line)
+		}
+	}
+	if len(p.Stack) > 0 {
+		frames := make([]string, len(p.Stack))
+		for i, f := range p.Stack {
+			frames[i] = f.Func + "\n\t" + f.File + ":" + strconv.Itoa(f.Line)
+		}
+		blocks = append(blocks, strings.Join(frames, "\n"))
+	}
+	return blocks
+}
+
+// testParsedBlocks checks that the error parsed from the %+v output dump has the
+// blocks expected by testFormatCompleteCompare.
+func testParsedBlocks(t *testing.T, n int, dump string, want []string, detectStackBoundaries bool) {
+	t.Helper()
+	parsed, err := ParseErrorStack(dump)
+	require.NoError(t, err, "test %d", n+1)
+	var got []string
+	for _, b := range parsedBlocks(parsed) {
+		// stack traces printed after each other are one block without detectStackBoundaries.
+		if last := len(got) - 1; !detectStackBoundaries && last >= 0 && strings.Contains(got[last], "\n") && strings.Contains(b, "\n") {
+			got[last] += "\n" + b
+			continue
+		}
+		got = append(got, b)
+	}
+	require.Len(t, got, len(want), "test %d: ParseErrorStack(%q)", n+1, dump)
+	for i := range got {
+		if strings.Contains(want[i], "\n") {
+			require.Regexp(t, want[i], got[i], "test %d: block %d", n+1, i+1)
+		} else {
+			require.Equal(t, want[i], got[i], "test %d: block %d", n+1, i+1)
+		}
+	}
+}
+
+// testParsedRegexp checks that the error parsed from the %+v output dump matches
+// the lines expected by testFormatRegexp.
+func testParsedRegexp(t *testing.T, n int, dump string, want string) {
+	t.Helper()
+	parsed, err := ParseErrorStack(dump)
+	require.NoError(t, err, "test %d", n+1)
+	gotLines := strings.Split(strings.Join(parsedBlocks(parsed), "\n"), "\n")
+	wantLines := strings.Split(want, "\n")
+	require.GreaterOrEqual(t, len(gotLines), len(wantLines), "test %d: ParseErrorStack(%q)", n+1, dump)
+	for i, w := range wantLines {
+		require.Regexp(t, w, gotLines[i], "test %d: line %d", n+1, i+1)
+	}
+}
+
+func TestParseErrorStackFrames(t *testing.T) {
+	inner := WithStack(io.EOF)
+	outer := WithStack(inner)
+
+	parsed, err := ParseErrorStack(ErrorStack(outer))
+	require.NoError(t, err)
+	layers := parsed.Layers()
+	require.Len(t, layers, 2)
+	require.Equal(t, "", layers[0].Message)
+	require.Equal(t, outer.(*withStack).StackTrace().Frames(), layers[0].Stack)
+	require.Equal(t, "EOF", layers[1].Message)
+	require.Equal(t, inner.(*withStack).StackTrace().Frames(), layers[1].Stack)
+}
+
+func TestParseErrorStackCode(t *testing.T) {
+	errTest := Normalize("named error: %s", RFCCodeText("Internal:Test"))
+	err := Annotate(errTest.Wrap(io.EOF).GenWithStackByArgs("wrapped"), "annotated")
+
+	parsed, perr := ParseErrorStack(ErrorStack(err))
+	require.NoError(t, perr)
+	layers := parsed.Layers()
+	require.Len(t, layers, 3)
+	require.Equal(t, "annotated", layers[0].Message)
+	require.Equal(t, RFCErrorCode(""), layers[0].Code)
+	require.Equal(t, "named error: wrapped", layers[1].Message)
+	require.Equal(t, RFCErrorCode("Internal:Test"), layers[1].Code)
+	require.Equal(t, "EOF", parsed.RootCause().Message)
+	require.Equal(t, "github.com/pingcap/errors.(*Error).GenWithStackByArgs", layers[1].Stack[0].Func)
+	require.Equal(t, "github.com/pingcap/errors.TestParseErrorStackCode", layers[1].Stack[1].Func)
+}
+
+func TestParseErrorStackMetadata(t *testing.T) {
+	EnableStackMetadata()
+	defer DisableStackMetadata()
+
+	err := New("with metadata")
+	parsed, perr := ParseErrorStack(ErrorStack(err))
+	require.NoError(t, perr)
+	require.Equal(t, "with metadata", parsed.Message)
+	require.Nil(t, parsed.Cause)
+	m := GetStackMetadata(err)
+	require.Equal(t, m.GoroutineID(), parsed.GoroutineID)
+	require.True(t, m.CreatedAt().Equal(parsed.CreatedAt))
+	require.NotEmpty(t, parsed.Stack)
+}
+
+func TestParseErrorStackInvalid(t *testing.T) {
+	for _, dump := range []string{
+		"",
+		"fn\n\tfile.go:1",
+		"msg\n\tfile.go:1",
+		"msg\nfn\n\tfile.go",
+		"msg\nfn\n\tfile.go:x",
+		"msg\n(goroutine x, created at now)",
+	} {
+		_, err := ParseErrorStack(dump)
+		require.Error(t, err, "%q", dump)
+	}
+}
+
+func TestParseErrorStackJoin(t *testing.T) {
+	err := Annotate(Join(New("first"), Join(io.EOF, Annotate(New("second"), "ctx"))), "joined")
+	parsed, perr := ParseErrorStack(ErrorStack(err))
+	require.NoError(t, perr)
+	require.Equal(t, "joined", parsed.Message)
+	require.NotEmpty(t, parsed.Stack)
+
+	join := parsed.Cause
+	require.Nil(t, join.Cause)
+	require.Empty(t, join.Message)
+	require.Len(t, join.Errors, 2)
+	require.Equal(t, "first", join.Errors[0].Message)
+	require.NotEmpty(t, join.Errors[0].Stack)
+
+	nested := join.Errors[1]
+	require.Len(t, nested.Errors, 2)
+	require.Equal(t, "EOF", nested.Errors[0].Message)
+	require.Equal(t, "ctx", nested.Errors[1].Message)
+	require.Equal(t, "second", nested.Errors[1].Cause.Message)
+	require.NotEmpty(t, nested.Errors[1].Cause.Stack)
+	require.Same(t, join, parsed.RootCause())
+}
+
+func TestParseErrorStackMultiline(t *testing.T) {
+	err := WithMessage(Annotate(New("first\nsecond"), "annotated\n\tindented"), "outer")
+	parsed, perr := ParseErrorStack(ErrorStack(err))
+	require.NoError(t, perr)
+	layers := parsed.Layers()
+	require.Len(t, layers, 3)
+	require.Equal(t, "outer", layers[0].Message)
+	require.Equal(t, "annotated\n\tindented", layers[1].Message)
+	require.Empty(t, layers[1].Stack)
+	require.Equal(t, "first\nsecond", layers[2].Message)
+	require.NotEmpty(t, layers[2].Stack)
+
+	_, perr = ParseErrorStack("msg\n" + ErrorStack(New("error")) + "\n  indented")
+	require.Error(t, perr)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Pooled returns a NormalizeOption making the FastGen* methods take the errors
+// they generate from a pool, so that generating them does not allocate once
+// they are given back with Release. It is meant for the hottest errors, like
+// the ones retried in loops:
+//
+//	var ErrWriteConflict = errors.Normalize("write conflict, txnStartTS=%d",
+//	    errors.RFCCodeText("tikv:kv:WriteConflict"), errors.Pooled())
+//
+//	for {
+//	    err := ErrWriteConflict.FastGenByArgs(startTS)
+//	    ...
+//	    errors.Release(err)
+//	}
+//
+// The errors generated by the other methods are not pooled.
+func Pooled() NormalizeOption {
+	return func(e *Error) {
+		e.def.pool = &sync.Pool{New: func() interface{} { return &fastError{} }}
+	}
+}
+
+// Release gives the outermost *Error of the chain of err back to the pool it
+// was taken from, if it was generated by a FastGen* method of a Pooled error.
+// It does nothing otherwise, and for nil.
+//
+// Neither err nor any error wrapping it or returned by its methods, like Args,
+// may be used after Release. Builds with the errorsdebug build tag panic when
+// a released error is used or released again, instead of reusing it.
+func Release(err error) {
+	for ; err != nil; err = DirectCause(err) {
+		if e, ok := err.(*Error); ok {
+			if e.pooled != nil {
+				e.pooled.release()
+			}
+			return
+		}
+	}
+}
+
+func (f *fastError) release() {
+	if poolDebug {
+		if f.setReleased() {
+			panic(fmt.Sprintf("errors: %s released twice", f.Error.codeText))
+		}
+		// the error is not reused, so that its use is detected.
+		return
+	}
+	pool := f.Error.def.pool
+	// drop the references held by the error for the GC.
+	*f = fastError{}
+	pool.Put(f)
+}
+
+// checkReleased panics in debug builds if e was released, see Release. It
+// accepts a nil e, for the methods supporting nil receivers.
+func (e *Error) checkReleased() {
+	if poolDebug && e != nil && e.pooled != nil && e.pooled.isReleased() {
+		panic(fmt.Sprintf("errors: %s used after Release", e.codeText))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build errorsdebug
+// +build errorsdebug
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseDebug(t *testing.T) {
+	errConflict := Normalize("write conflict, txnStartTS=%d", RFCCodeText("test-pool:kv:WriteConflict"), Pooled())
+	err := errConflict.FastGenByArgs(1)
+	Release(err)
+	require.PanicsWithValue(t, "errors: test-pool:kv:WriteConflict used after Release", func() { _ = err.Error() })
+	require.Panics(t, func() { _ = err.(*Error).Args() })
+	require.Panics(t, func() { _ = err.(*Error).FastGenByArgs(2) })
+	require.PanicsWithValue(t, "errors: test-pool:kv:WriteConflict released twice", func() { Release(err) })
+
+	// released errors are not reused.
+	require.NotSame(t, err, errConflict.FastGenByArgs(1))
+}
+
+func TestReleaseDebugAccessors(t *testing.T) {
+	errConflict := Normalize("write conflict, txnStartTS=%d", RFCCodeText("test-pool:kv:Accessors"), Pooled())
+	released := errConflict.FastGenByArgs(1).(*Error)
+	Release(released)
+	for name, use := range map[string]func(){
+		"Code":               func() { released.Code() },
+		"RFCCode":            func() { released.RFCCode() },
+		"ID":                 func() { released.ID() },
+		"Location":           func() { released.Location() },
+		"MessageTemplate":    func() { released.MessageTemplate() },
+		"Args":               func() { released.Args() },
+		"Error":              func() { _ = released.Error() },
+		"GetMsg":             func() { released.GetMsg() },
+		"GetSelfMsg":         func() { released.GetSelfMsg() },
+		"RedactedMsg":        func() { released.RedactedMsg() },
+		"Equal":              func() { released.Equal(errConflict) },
+		"NotEqual":           func() { released.NotEqual(errConflict) },
+		"Is":                 func() { released.Is(errConflict) },
+		"Unwrap":             func() { released.Unwrap() },
+		"Cause":              func() { released.Cause() },
+		"Wrap":               func() { released.Wrap(errConflict) },
+		"Severity":           func() { released.Severity() },
+		"Category":           func() { released.Category() },
+		"SQLState":           func() { released.SQLState() },
+		"NamedTemplate":      func() { released.NamedTemplate() },
+		"ArgNames":           func() { released.ArgNames() },
+		"PreviousRFCCodes":   func() { released.PreviousRFCCodes() },
+		"PreviousMySQLCodes": func() { released.PreviousMySQLCodes() },
+		"ParsedRFCCode":      func() { _, _ = released.ParsedRFCCode() },
+		"MarshalJSON":        func() { _, _ = released.MarshalJSON() },
+		"UnmarshalJSON":      func() { _ = released.UnmarshalJSON([]byte(`{}`)) },
+		"GenWithStackByArgs": func() { released.GenWithStackByArgs(2) },
+	} {
+		require.PanicsWithValue(t, "errors: test-pool:kv:Accessors used after Release", use, name)
+	}
+	// fmt recovers the panics of Format.
+	require.Contains(t, ErrorStack(released), "PANIC=Format method: errors: test-pool:kv:Accessors used after Release")
+
+	var nilErr *Error
+	require.Equal(t, "<nil>", ErrorStack(nilErr))
+	require.Nil(t, nilErr.Unwrap())
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !errorsdebug
+// +build !errorsdebug
+
+package errors
+
+// poolDebug enables the detection of the use of released errors, see Release.
+const poolDebug = false
+
+// poolState is empty, released errors are not detected.
+type poolState struct{}
+
+func (s *poolState) setReleased() bool { return false }
+
+func (s *poolState) isReleased() bool { return false }
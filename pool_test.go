@@ -0,0 +1,68 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPooled(t *testing.T) {
+	errConflict := Normalize("write conflict, txnStartTS=%d", RFCCodeText("test-pool:kv:WriteConflict"), Pooled())
+	for i := 0; i < 10; i++ {
+		err := errConflict.FastGenByArgs(i)
+		require.Equal(t, []interface{}{i}, err.(*Error).Args())
+		require.Equal(t, "[test-pool:kv:WriteConflict]write conflict, txnStartTS="+strconv.Itoa(i), err.Error())
+		require.True(t, errConflict.Equal(err))
+		require.False(t, HasStack(err))
+		Release(Trace(err))
+	}
+
+	// the errors generated from a pooled error are pooled too.
+	err := errConflict.FastGenByArgs(1)
+	require.NotNil(t, err.(*Error).FastGenByArgs(2).(*Error).pooled)
+	Release(err)
+
+	// the errors of the other methods, and the one without args, are not.
+	require.Nil(t, errConflict.GenWithStackByArgs(1).(*withStack).error.(*Error).pooled)
+	require.Nil(t, errConflict.FastGenByArgs().(*Error).pooled)
+	Release(errConflict.FastGenByArgs())
+	Release(errConflict)
+	Release(New("not pooled"))
+	Release(nil)
+	require.Equal(t, "[test-pool:kv:WriteConflict]write conflict, txnStartTS=%d", errConflict.Error())
+}
+
+func TestPooledConcurrent(t *testing.T) {
+	errConflict := Normalize("write conflict, txnStartTS=%d, key=%s", RFCCodeText("test-pool:kv:WriteConflict"), Pooled())
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				err := errConflict.FastGenByArgs(i, "k")
+				if got, want := err.(*Error).Args()[0], i; got != want {
+					t.Errorf("got %v, want %v", got, want)
+				}
+				_ = err.Error()
+				Release(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,267 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem documents in JSON.
+const ProblemContentType = "application/problem+json"
+
+// Extension members of the problem documents written by ToProblem.
+const (
+	ProblemCodeMember      = "code"
+	ProblemMySQLCodeMember = "mysql_code"
+	ProblemArgsMember      = "args"
+)
+
+// Problem is an RFC 7807 problem details document. The extension members are
+// marshaled next to the standard ones.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(p.Extensions)+len(problemMembers))
+	for k, v := range p.Extensions {
+		doc[k] = v
+	}
+	for i, v := range []interface{}{p.Type, p.Title, p.Status, p.Detail, p.Instance} {
+		if v != "" && v != 0 {
+			doc[problemMembers[i]] = v
+		} else {
+			delete(doc, problemMembers[i])
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface. Members of unexpected
+// types are left in Extensions.
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return Trace(err)
+	}
+	*p = Problem{}
+	take := func(member string, dst *string) {
+		if s, ok := doc[member].(string); ok {
+			*dst = s
+			delete(doc, member)
+		}
+	}
+	take("type", &p.Type)
+	take("title", &p.Title)
+	take("detail", &p.Detail)
+	take("instance", &p.Instance)
+	if status, ok := doc["status"].(float64); ok {
+		p.Status = int(status)
+		delete(doc, "status")
+	}
+	if len(doc) > 0 {
+		p.Extensions = doc
+	}
+	return nil
+}
+
+var (
+	problemMu          sync.RWMutex
+	problemTypeBase    = "urn:pingcap:error:"
+	problemStatus      = map[RFCErrorCode]int{}
+	problemDefaultCode = 500
+)
+
+// SetProblemTypeBase sets the prefix of the type URI of the problems made by
+// ToProblem, followed by the RFC code. It is "urn:pingcap:error:" by default,
+// it can be set to the URL of the error documentation instead.
+func SetProblemTypeBase(base string) {
+	problemMu.Lock()
+	defer problemMu.Unlock()
+	problemTypeBase = base
+}
+
+// SetProblemStatus sets the HTTP status of the problems made by ToProblem for
+// errors with the given RFC code. A status of 0 removes it, so the default applies again.
+func SetProblemStatus(code RFCErrorCode, status int) {
+	problemMu.Lock()
+	defer problemMu.Unlock()
+	if status == 0 {
+		delete(problemStatus, code)
+		return
+	}
+	problemStatus[code] = status
+}
+
+// SetDefaultProblemStatus sets the HTTP status of the problems made by ToProblem
+// for errors without a status of their own. It is 500 by default.
+func SetDefaultProblemStatus(status int) {
+	problemMu.Lock()
+	defer problemMu.Unlock()
+	problemDefaultCode = status
+}
+
+// RedactedMsg returns the message of e like GetMsg, with the args at the positions
+// set by RedactArgs replaced by "?" whatever RedactLogEnabled is. It is meant for
+// messages leaving the process, like HTTP responses.
+func (e *Error) RedactedMsg() string {
+	e.checkReleased()
+	return e.redactedMsg(RedactLogEnable)
+}
+
+// redactedMsg renders the message of e, redacting its args as RedactErrorArg
+// does in the given mode. Args already redacted are not redacted twice.
+func (e *Error) redactedMsg(mode string) string {
+	if e.rendered || len(e.args) == 0 || len(e.definition().redactArgsPos) == 0 {
+		return e.GetMsg()
+	}
+	if mode != RedactLogEnable && mode != RedactLogMarker {
+		return e.GetMsg()
+	}
+	args := append([]interface{}(nil), e.args...)
+	for _, pos := range e.definition().redactArgsPos {
+		if pos >= len(args) {
+			continue
+		}
+		if rf, ok := args[pos].(*redactFormatter); ok {
+			args[pos] = rf.arg
+		}
+		if mode == RedactLogEnable {
+			args[pos] = "?"
+		} else {
+			args[pos] = &redactFormatter{args[pos]}
+		}
+	}
+	return fmt.Sprintf(e.message, args...)
+}
+
+// ToProblem converts err to an RFC 7807 problem document, from the outermost
+// *Error of its chain:
+//
+//	type        the RFC code appended to the base set by SetProblemTypeBase
+//	title       the message template
+//	status      the HTTP status set by SetProblemStatus for the RFC code, or the default one
+//	detail      the message, redacted by RedactedMsg
+//	code        the RFC code
+//	mysql_code  the MySQL error code
+//	args        the args formatted with %v, redacted like the message
+//
+// An error without *Error gets the type "about:blank", the default status and
+// err.Error() as detail, without title: writers like httperr.WriteProblem fill
+// in the text of the status. It returns nil for nil.
+func ToProblem(err error) *Problem {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	for _, layer := range Chain(err) {
+		if x, ok := layer.(*Error); ok {
+			e = x
+			break
+		}
+	}
+
+	problemMu.RLock()
+	defer problemMu.RUnlock()
+	if e == nil {
+		return &Problem{
+			Type:   "about:blank",
+			Status: problemDefaultCode,
+			Detail: err.Error(),
+		}
+	}
+	status, ok := problemStatus[e.RFCCode()]
+	if !ok {
+		status = problemDefaultCode
+	}
+	p := &Problem{
+		Type:   problemTypeBase + string(e.RFCCode()),
+		Title:  e.message,
+		Status: status,
+		Detail: e.RedactedMsg(),
+		Extensions: map[string]interface{}{
+			ProblemCodeMember:      string(e.RFCCode()),
+			ProblemMySQLCodeMember: int(e.code),
+		},
+	}
+	if len(e.args) > 0 {
+		args := make([]string, len(e.args))
+		for i, arg := range e.args {
+			args[i] = fmt.Sprint(arg)
+		}
+		for _, pos := range e.definition().redactArgsPos {
+			if pos < len(args) {
+				args[pos] = "?"
+			}
+		}
+		p.Extensions[ProblemArgsMember] = args
+	}
+	return p
+}
+
+// FromProblem rebuilds the *Error a problem document was made from by ToProblem,
+// so that it is equal to its prototype. The RFC code is taken from the code
+// member, or else from the type. Like the errors decoded by UnmarshalJSON, its
+// args are strings and GetMsg returns the detail until new args are given.
+// It returns nil if p has no RFC code nor MySQL code.
+func FromProblem(p *Problem) *Error {
+	if p == nil {
+		return nil
+	}
+	j := &jsonError{
+		Msg:      p.Detail,
+		Template: p.Title,
+	}
+	if code, ok := p.Extensions[ProblemCodeMember].(string); ok {
+		j.RFCCode = code
+	} else {
+		problemMu.RLock()
+		base := problemTypeBase
+		problemMu.RUnlock()
+		if strings.HasPrefix(p.Type, base) {
+			j.RFCCode = strings.TrimPrefix(p.Type, base)
+		}
+	}
+	switch code := p.Extensions[ProblemMySQLCodeMember].(type) {
+	case int:
+		j.Code = code
+	case float64:
+		j.Code = int(code)
+	}
+	if j.RFCCode == "" && j.Code == 0 {
+		return nil
+	}
+	switch args := p.Extensions[ProblemArgsMember].(type) {
+	case []string:
+		j.Args = args
+	case []interface{}:
+		for _, arg := range args {
+			j.Args = append(j.Args, fmt.Sprint(arg))
+		}
+	}
+	e := &Error{}
+	e.fromJSON(j)
+	return e
+}
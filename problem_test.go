@@ -0,0 +1,104 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblem(t *testing.T) {
+	errNotFound := Normalize("table %s.%s doesn't exist", RFCCodeText("test-problem:schema:TableNotExists"),
+		MySQLErrorCode(1146), RedactArgs([]int{1}))
+	SetProblemStatus(errNotFound.RFCCode(), 404)
+	defer SetProblemStatus(errNotFound.RFCCode(), 0)
+
+	p := ToProblem(Annotate(errNotFound.GenWithStackByArgs("test", "secret"), "load table"))
+	require.Equal(t, "urn:pingcap:error:test-problem:schema:TableNotExists", p.Type)
+	require.Equal(t, "table %s.%s doesn't exist", p.Title)
+	require.Equal(t, 404, p.Status)
+	require.Equal(t, "table test.? doesn't exist", p.Detail)
+	require.Equal(t, "test-problem:schema:TableNotExists", p.Extensions[ProblemCodeMember])
+	require.Equal(t, 1146, p.Extensions[ProblemMySQLCodeMember])
+	require.Equal(t, []string{"test", "?"}, p.Extensions[ProblemArgsMember])
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	p = &Problem{}
+	require.NoError(t, json.Unmarshal(data, p))
+	e := FromProblem(p)
+	require.True(t, errNotFound.Equal(e))
+	require.Equal(t, ErrCode(1146), e.Code())
+	require.Equal(t, "table test.? doesn't exist", e.GetMsg())
+	require.Equal(t, []interface{}{"test", "?"}, e.Args())
+	require.Equal(t, "[test-problem:schema:TableNotExists]table other.t doesn't exist", e.FastGenByArgs("other", "t").Error())
+
+	p = ToProblem(io.EOF)
+	require.Equal(t, "about:blank", p.Type)
+	require.Equal(t, "", p.Title)
+	require.Equal(t, 500, p.Status)
+	require.Equal(t, "EOF", p.Detail)
+	require.Nil(t, FromProblem(p))
+}
+
+func TestProblemConfig(t *testing.T) {
+	errConflict := Normalize("write conflict", RFCCodeText("test-problem:kv:Conflict"))
+	SetProblemTypeBase("https://example.com/errors/")
+	SetDefaultProblemStatus(503)
+	defer func() {
+		SetProblemTypeBase("urn:pingcap:error:")
+		SetDefaultProblemStatus(500)
+	}()
+
+	p := ToProblem(errConflict.FastGenByArgs())
+	require.Equal(t, "https://example.com/errors/test-problem:kv:Conflict", p.Type)
+	require.Equal(t, 503, p.Status)
+	require.Nil(t, ToProblem(nil))
+
+	// the RFC code is taken from the type without code member.
+	delete(p.Extensions, ProblemCodeMember)
+	require.True(t, errConflict.Equal(FromProblem(p)))
+}
+
+func TestProblemJSON(t *testing.T) {
+	p := &Problem{
+		Type:       "about:blank",
+		Status:     400,
+		Extensions: map[string]interface{}{"title": "overridden", "balance": 30},
+	}
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"about:blank","status":400,"balance":30}`, string(data))
+
+	decoded := &Problem{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.Equal(t, "about:blank", decoded.Type)
+	require.Equal(t, 400, decoded.Status)
+	require.Equal(t, map[string]interface{}{"balance": float64(30)}, decoded.Extensions)
+}
+
+func TestRedactedMsg(t *testing.T) {
+	errRedacted := Normalize("Duplicate entry '%s' for key '%s'", RedactArgs([]int{0}))
+	err := errRedacted.FastGenByArgs("secret", "PRIMARY").(*Error)
+	require.Equal(t, "Duplicate entry 'secret' for key 'PRIMARY'", err.GetMsg())
+	require.Equal(t, "Duplicate entry '?' for key 'PRIMARY'", err.RedactedMsg())
+
+	RedactLogEnabled.Store(RedactLogMarker)
+	defer RedactLogEnabled.Store(RedactLogDisable)
+	err = errRedacted.FastGenByArgs("secret", "PRIMARY").(*Error)
+	require.Equal(t, "Duplicate entry '?' for key 'PRIMARY'", err.RedactedMsg())
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"go.uber.org/atomic"
+)
+
+// RFCCode is a well-formed RFC error code: a 3-tuple of abbreviated component
+// name, error class and inner error code, written as {Component}:{ErrorClass}:{InnerErrorCode}.
+// Use NewRFCCode or ParseRFCCode to make one.
+type RFCCode struct {
+	component string
+	class     string
+	inner     string
+}
+
+// NewRFCCode builds an RFCCode from its parts. Every part must be non-empty and
+// must not contain colons, spaces or control characters.
+func NewRFCCode(component, class, inner string) (RFCCode, error) {
+	for _, part := range []struct{ name, value string }{
+		{"component", component},
+		{"class", class},
+		{"inner code", inner},
+	} {
+		if err := validateRFCCodePart(part.value); err != nil {
+			return RFCCode{}, Annotatef(err, "invalid %s of RFC code %s:%s:%s", part.name, component, class, inner)
+		}
+	}
+	return RFCCode{component: component, class: class, inner: inner}, nil
+}
+
+// ParseRFCCode parses a textual RFC code like "tikv:kv:WriteConflict".
+func ParseRFCCode(code string) (RFCCode, error) {
+	parts := strings.Split(code, ":")
+	if len(parts) != 3 {
+		return RFCCode{}, Errorf("RFC code %q is not in the form {Component}:{ErrorClass}:{InnerErrorCode}", code)
+	}
+	return NewRFCCode(parts[0], parts[1], parts[2])
+}
+
+func validateRFCCodePart(part string) error {
+	if part == "" {
+		return New("empty")
+	}
+	for _, c := range part {
+		if c == ':' || unicode.IsSpace(c) || unicode.IsControl(c) {
+			return Errorf("unexpected character %q", c)
+		}
+	}
+	return nil
+}
+
+// Component returns the abbreviated component name.
+func (c RFCCode) Component() string { return c.component }
+
+// Class returns the error class.
+func (c RFCCode) Class() string { return c.class }
+
+// Inner returns the inner error code.
+func (c RFCCode) Inner() string { return c.inner }
+
+// IsZero reports whether c is the zero value, which is not a valid code.
+func (c RFCCode) IsZero() bool { return c == RFCCode{} }
+
+// String returns the textual form of the code.
+func (c RFCCode) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return c.component + ":" + c.class + ":" + c.inner
+}
+
+// ErrorCode returns the code as returned by (*Error).RFCCode.
+func (c RFCCode) ErrorCode() RFCErrorCode { return RFCErrorCode(c.String()) }
+
+// ParsedRFCCode parses the RFC code of e, see ParseRFCCode.
+func (e *Error) ParsedRFCCode() (RFCCode, error) {
+	e.checkReleased()
+	return ParseRFCCode(string(e.RFCCode()))
+}
+
+// RFCCodeValidation tells Normalize what to do with a malformed RFC code.
+type RFCCodeValidation int32
+
+const (
+	// RFCCodeValidationOff accepts any code. It is the default.
+	RFCCodeValidationOff RFCCodeValidation = iota
+	// RFCCodeValidationPanic makes Normalize panic, it is meant for tests and
+	// development builds.
+	RFCCodeValidationPanic
+	// RFCCodeValidationRecord makes Normalize record the problem, it can be
+	// retrieved with RFCCodeValidationErrors.
+	RFCCodeValidationRecord
+)
+
+var (
+	rfcCodeValidation     atomic.Int32
+	rfcCodeValidationMu   sync.Mutex
+	rfcCodeValidationErrs []error
+)
+
+// SetRFCCodeValidation sets how Normalize validates the RFC code of the errors
+// it creates. Errors defined with MySQLErrorCode only, without RFCCodeText, are
+// not validated. As most prototypes are package level variables, call it before
+// the packages defining them are initialized, e.g. from an init function of a
+// package imported first, or check RFCCodeValidationErrors afterwards.
+func SetRFCCodeValidation(mode RFCCodeValidation) {
+	rfcCodeValidation.Store(int32(mode))
+}
+
+// RFCCodeValidationErrors returns the problems recorded in RFCCodeValidationRecord mode.
+func RFCCodeValidationErrors() []error {
+	rfcCodeValidationMu.Lock()
+	defer rfcCodeValidationMu.Unlock()
+	return append([]error(nil), rfcCodeValidationErrs...)
+}
+
+// ResetRFCCodeValidationErrors forgets the problems recorded so far.
+func ResetRFCCodeValidationErrors() {
+	rfcCodeValidationMu.Lock()
+	defer rfcCodeValidationMu.Unlock()
+	rfcCodeValidationErrs = nil
+}
+
+// validateNormalized validates the RFC code of an *Error created by Normalize.
+func validateNormalized(e *Error) {
+	mode := RFCCodeValidation(rfcCodeValidation.Load())
+	if mode == RFCCodeValidationOff || e.codeText == "" {
+		return
+	}
+	if _, err := ParseRFCCode(string(e.codeText)); err != nil {
+		err = Annotatef(err, "invalid error definition %q", e.message)
+		if mode == RFCCodeValidationPanic {
+			panic(err)
+		}
+		rfcCodeValidationMu.Lock()
+		rfcCodeValidationErrs = append(rfcCodeValidationErrs, err)
+		rfcCodeValidationMu.Unlock()
+	}
+}
+
+// Namespace defines errors whose RFC codes share a fixed component.
+//
+//	var tikv = errors.NewNamespace("tikv")
+//	var ErrWriteConflict = tikv.Normalize("kv", "WriteConflict", "write conflict on key %s")
+type Namespace struct {
+	component string
+}
+
+// NewNamespace returns the Namespace of the given component.
+func NewNamespace(component string) Namespace {
+	return Namespace{component: component}
+}
+
+// Component returns the component of the namespace.
+func (ns Namespace) Component() string { return ns.component }
+
+// Code returns the RFC code of the given class and inner code in the namespace.
+func (ns Namespace) Code(class, inner string) (RFCCode, error) {
+	return NewRFCCode(ns.component, class, inner)
+}
+
+// Normalize creates a new Error object like Normalize, with the RFC code
+// {Component}:{class}:{inner}. The code can not be overridden by opts.
+func (ns Namespace) Normalize(class, inner, message string, opts ...NormalizeOption) *Error {
+	code := ns.component + ":" + class + ":" + inner
+	return Normalize(message, append(opts[:len(opts):len(opts)], RFCCodeText(code))...)
+}
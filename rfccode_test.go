@@ -0,0 +1,89 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRFCCode(t *testing.T) {
+	code, err := ParseRFCCode("tikv:kv:WriteConflict")
+	require.NoError(t, err)
+	require.Equal(t, "tikv", code.Component())
+	require.Equal(t, "kv", code.Class())
+	require.Equal(t, "WriteConflict", code.Inner())
+	require.Equal(t, "tikv:kv:WriteConflict", code.String())
+	require.Equal(t, RFCErrorCode("tikv:kv:WriteConflict"), code.ErrorCode())
+	require.False(t, code.IsZero())
+
+	for _, invalid := range []string{
+		"", "Unavailable", "types:1292", "a:b:c:d", "a::c", ":b:c", "a:b:", "a b:c:d", "a:b\n:c",
+	} {
+		_, err := ParseRFCCode(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	_, err = NewRFCCode("tikv", "", "1")
+	require.Error(t, err)
+	require.Equal(t, "", RFCCode{}.String())
+
+	parsed, err := Normalize("x", RFCCodeText("ddl:schema:-1")).ParsedRFCCode()
+	require.NoError(t, err)
+	require.Equal(t, "-1", parsed.Inner())
+}
+
+func TestRFCCodeValidation(t *testing.T) {
+	defer SetRFCCodeValidation(RFCCodeValidationOff)
+	defer ResetRFCCodeValidationErrors()
+
+	// off by default, malformed codes are accepted.
+	require.Equal(t, ErrorID("Unavailable"), Normalize("x", RFCCodeText("Unavailable")).ID())
+
+	SetRFCCodeValidation(RFCCodeValidationPanic)
+	require.Panics(t, func() { Normalize("x", RFCCodeText("Unavailable")) })
+	require.NotPanics(t, func() { Normalize("x", RFCCodeText("tikv:region:Unavailable")) })
+	require.NotPanics(t, func() { Normalize("x", MySQLErrorCode(1062)) })
+
+	SetRFCCodeValidation(RFCCodeValidationRecord)
+	Normalize("region %d is unavailable", RFCCodeText("Unavailable"))
+	Normalize("ok", RFCCodeText("tikv:region:Unavailable"))
+	errs := RFCCodeValidationErrors()
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), `invalid error definition "region %d is unavailable"`)
+	ResetRFCCodeValidationErrors()
+	require.Empty(t, RFCCodeValidationErrors())
+}
+
+func TestNamespace(t *testing.T) {
+	tikv := NewNamespace("tikv")
+	require.Equal(t, "tikv", tikv.Component())
+
+	errConflict := tikv.Normalize("kv", "WriteConflict", "write conflict on key %s",
+		MySQLErrorCode(9007), RFCCodeText("other:code:Ignored"))
+	require.Equal(t, RFCErrorCode("tikv:kv:WriteConflict"), errConflict.RFCCode())
+	require.Equal(t, ErrCode(9007), errConflict.Code())
+	require.Equal(t, "[tikv:kv:WriteConflict]write conflict on key k", errConflict.GenWithStackByArgs("k").Error())
+
+	code, err := tikv.Code("region", "NotLeader")
+	require.NoError(t, err)
+	require.Equal(t, "tikv:region:NotLeader", code.String())
+	_, err = tikv.Code("region", "not leader")
+	require.Error(t, err)
+
+	SetRFCCodeValidation(RFCCodeValidationPanic)
+	defer SetRFCCodeValidation(RFCCodeValidationOff)
+	require.Panics(t, func() { NewNamespace("ti kv").Normalize("kv", "WriteConflict", "x") })
+}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"path"
@@ -64,6 +65,46 @@ func (f Frame) line() int {
 	return line
 }
 
+// Func returns the fully qualified name of the function for this Frame,
+// like "github.com/pingcap/errors.(*Error).GenWithStack".
+func (f Frame) Func() string {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown"
+	}
+	return fn.Name()
+}
+
+// File returns the full path to the source file of this Frame.
+func (f Frame) File() string { return f.file() }
+
+// Line returns the line number in the source file of this Frame.
+func (f Frame) Line() int { return f.line() }
+
+// Package returns the import path of the package of the function for this Frame.
+func (f Frame) Package() string {
+	return pkgname(f.Func())
+}
+
+// FrameInfo is the plain, JSON friendly description of a Frame.
+type FrameInfo struct {
+	Func    string `json:"func"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// Info returns the FrameInfo of this Frame.
+func (f Frame) Info() FrameInfo {
+	name := f.Func()
+	return FrameInfo{
+		Func:    name,
+		Package: pkgname(name),
+		File:    f.file(),
+		Line:    f.line(),
+	}
+}
+
 // Format formats the frame according to the fmt.Formatter interface.
 //
 //	%s    source file
@@ -144,6 +185,21 @@ func (st StackTrace) Format(s fmt.State, verb rune) {
 	io.Copy(s, &b)
 }
 
+// Frames returns the FrameInfo of each Frame in the stack, innermost first.
+func (st StackTrace) Frames() []FrameInfo {
+	frames := make([]FrameInfo, len(st))
+	for i, f := range st {
+		frames[i] = f.Info()
+	}
+	return frames
+}
+
+// MarshalJSON implements json.Marshaler, a StackTrace is encoded as the array
+// returned by Frames.
+func (st StackTrace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.Frames())
+}
+
 // formatSlice will format this StackTrace into the given buffer as a slice of
 // Frame, only valid when called with '%s' or '%v'.
 func (st StackTrace) formatSlice(b *bytes.Buffer, s fmt.State, verb rune) {
@@ -203,11 +259,17 @@ func callers() *stack {
 	return callersSkip(4)
 }
 
+// maxStackDepth is the most frames recorded in a stack trace.
+const maxStackDepth = 32
+
 func callersSkip(skip int) *stack {
-	const depth = 32
-	var pcs [depth]uintptr
+	var pcs [maxStackDepth]uintptr
 	n := runtime.Callers(skip, pcs[:])
-	var st stack = pcs[0:n]
+	if in := loadStackInterner(); in != nil {
+		return in.intern(pcs[:n])
+	}
+	st := make(stack, n)
+	copy(st, pcs[:n])
 	return &st
 }
 
@@ -219,6 +281,16 @@ func funcname(name string) string {
 	return name[i+1:]
 }
 
+// pkgname returns the package path component of a function's name reported by func.Name().
+func pkgname(name string) string {
+	i := strings.LastIndex(name, "/")
+	j := strings.Index(name[i+1:], ".")
+	if j < 0 {
+		return ""
+	}
+	return name[:i+1+j]
+}
+
 // NewStack is for library implementers that want to generate a stack trace.
 // Normally you should insted use AddStack to get an error with a stack trace.
 //
@@ -0,0 +1,99 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPkgname(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"", ""},
+		{"runtime.main", "runtime"},
+		{"github.com/pingcap/errors.funcname", "github.com/pingcap/errors"},
+		{"funcname", ""},
+		{"main.(*R).Write", "main"},
+		{"github.com/pingcap/errors.(*X).ptr", "github.com/pingcap/errors"},
+	}
+
+	for _, tt := range tests {
+		got := pkgname(tt.name)
+		if got != tt.want {
+			t.Errorf("pkgname(%q): want: %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFrameAccessors(t *testing.T) {
+	pc, file, line, _ := runtime.Caller(0)
+	f := Frame(pc)
+	if got := f.Func(); got != "github.com/pingcap/errors.TestFrameAccessors" {
+		t.Errorf("Func(): got %q", got)
+	}
+	if got := f.Package(); got != "github.com/pingcap/errors" {
+		t.Errorf("Package(): got %q", got)
+	}
+	if got := f.File(); got != file {
+		t.Errorf("File(): want %q, got %q", file, got)
+	}
+	if got := f.Line(); got != line {
+		t.Errorf("Line(): want %d, got %d", line, got)
+	}
+
+	want := FrameInfo{Func: "unknown", File: "unknown"}
+	if got := Frame(0).Info(); got != want {
+		t.Errorf("Info(): want %+v, got %+v", want, got)
+	}
+}
+
+func TestStackTraceFrames(t *testing.T) {
+	st := New("frames").(StackTracer).StackTrace()
+	_, _, line, _ := runtime.Caller(0)
+	frames := st.Frames()
+	if len(frames) != len(st) {
+		t.Fatalf("Frames(): want %d frames, got %d", len(st), len(frames))
+	}
+	want := FrameInfo{
+		Func:    "github.com/pingcap/errors.TestStackTraceFrames",
+		Package: "github.com/pingcap/errors",
+		File:    st[0].File(),
+		Line:    line - 1,
+	}
+	if frames[0] != want {
+		t.Errorf("Frames()[0]: want %+v, got %+v", want, frames[0])
+	}
+	if !strings.HasSuffix(frames[0].File, "/stack_export_test.go") {
+		t.Errorf("Frames()[0].File: got %q", frames[0].File)
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `[{"func":"github.com/pingcap/errors.TestStackTraceFrames","package":"github.com/pingcap/errors","file":`) {
+		t.Errorf("json.Marshal(StackTrace): got %s", data)
+	}
+	var decoded []FrameInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(frames) || decoded[0] != frames[0] {
+		t.Errorf("json.Unmarshal(StackTrace): got %+v", decoded)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// DefaultStackInternCapacity is the number of distinct stacks kept by
+// EnableStackInterning when a non-positive capacity is given.
+const DefaultStackInternCapacity = 4096
+
+// StackInternStats reports the state of the stack interning table.
+type StackInternStats struct {
+	Enabled   bool
+	Capacity  int
+	Entries   int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// stackInterner shares the storage of identical program counter slices.
+// Stacks handed out by it are immutable: nothing in this package writes
+// through a *stack, clearStack only replaces the pointer.
+type stackInterner struct {
+	mu       sync.RWMutex
+	capacity int
+	entries  map[uint64]*stack
+	// ring holds the keys in insertion order, the oldest one is evicted
+	// when the table is full.
+	ring []uint64
+	next int
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+var stackInternTable atomic.Value
+
+func init() {
+	stackInternTable.Store((*stackInterner)(nil))
+}
+
+// EnableStackInterning makes errors created at the same call site share one
+// copy of their stack trace. At most capacity distinct stacks are retained,
+// the oldest one is evicted once the limit is reached. Calling it again
+// replaces the table and drops all interned stacks.
+func EnableStackInterning(capacity int) {
+	if capacity <= 0 {
+		capacity = DefaultStackInternCapacity
+	}
+	stackInternTable.Store(&stackInterner{
+		capacity: capacity,
+		entries:  make(map[uint64]*stack, capacity),
+		ring:     make([]uint64, 0, capacity),
+	})
+}
+
+// DisableStackInterning turns interning off, every captured stack gets its
+// own storage again. Errors created before keep the stacks they share.
+func DisableStackInterning() {
+	stackInternTable.Store((*stackInterner)(nil))
+}
+
+// GetStackInternStats returns a snapshot of the interning table.
+func GetStackInternStats() StackInternStats {
+	in := loadStackInterner()
+	if in == nil {
+		return StackInternStats{}
+	}
+	in.mu.RLock()
+	entries := len(in.entries)
+	in.mu.RUnlock()
+	return StackInternStats{
+		Enabled:   true,
+		Capacity:  in.capacity,
+		Entries:   entries,
+		Hits:      in.hits.Load(),
+		Misses:    in.misses.Load(),
+		Evictions: in.evictions.Load(),
+	}
+}
+
+func loadStackInterner() *stackInterner {
+	return stackInternTable.Load().(*stackInterner)
+}
+
+// intern returns the shared stack equal to pcs, storing a copy of pcs if
+// there is none yet. pcs itself is never retained.
+func (in *stackInterner) intern(pcs []uintptr) *stack {
+	key := hashPCs(pcs)
+
+	in.mu.RLock()
+	st, ok := in.entries[key]
+	in.mu.RUnlock()
+	if ok && equalPCs(*st, pcs) {
+		in.hits.Inc()
+		return st
+	}
+	in.misses.Inc()
+
+	fresh := make(stack, len(pcs))
+	copy(fresh, pcs)
+	st = &fresh
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if old, ok := in.entries[key]; ok {
+		// Either another goroutine won the race or it is a hash collision,
+		// in both cases the newest stack takes over the slot.
+		if equalPCs(*old, pcs) {
+			return old
+		}
+		in.entries[key] = st
+		return st
+	}
+	if len(in.ring) < in.capacity {
+		in.ring = append(in.ring, key)
+	} else {
+		delete(in.entries, in.ring[in.next])
+		in.ring[in.next] = key
+		in.next = (in.next + 1) % in.capacity
+		in.evictions.Inc()
+	}
+	in.entries[key] = st
+	return st
+}
+
+// hashPCs is FNV-1a over the program counters.
+func hashPCs(pcs []uintptr) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	for _, pc := range pcs {
+		for i := 0; i < 8; i++ {
+			h ^= uint64(pc>>(8*i)) & 0xff
+			h *= prime64
+		}
+	}
+	return h
+}
+
+func equalPCs(a stack, b []uintptr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
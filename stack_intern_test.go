@@ -0,0 +1,78 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newInLoop(n int) []error {
+	errs := make([]error, 0, n)
+	for i := 0; i < n; i++ {
+		errs = append(errs, New("interned"))
+	}
+	return errs
+}
+
+func TestStackInterning(t *testing.T) {
+	errs := newInLoop(2)
+	require.NotSame(t, errs[0].(*fundamental).stack, errs[1].(*fundamental).stack)
+	require.False(t, GetStackInternStats().Enabled)
+
+	EnableStackInterning(0)
+	defer DisableStackInterning()
+
+	errs = newInLoop(3)
+	require.Same(t, errs[0].(*fundamental).stack, errs[1].(*fundamental).stack)
+	require.Same(t, errs[1].(*fundamental).stack, errs[2].(*fundamental).stack)
+	require.Equal(t, fmt.Sprintf("%+v", errs[0]), fmt.Sprintf("%+v", errs[2]))
+
+	stats := GetStackInternStats()
+	require.True(t, stats.Enabled)
+	require.Equal(t, DefaultStackInternCapacity, stats.Capacity)
+	require.Equal(t, 1, stats.Entries)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, uint64(2), stats.Hits)
+
+	// clearing the stack of one error must not affect the others sharing it.
+	SuspendStack(errs[0])
+	require.False(t, HasStack(errs[0]))
+	require.True(t, HasStack(errs[1]))
+}
+
+func TestStackInterningEviction(t *testing.T) {
+	EnableStackInterning(2)
+	defer DisableStackInterning()
+
+	var as []error
+	var b, c error
+	for i := 0; i < 2; i++ {
+		as = append(as, New("a"))
+		if i == 0 {
+			b, c = New("b"), New("c")
+			stats := GetStackInternStats()
+			require.Equal(t, 2, stats.Entries)
+			require.Equal(t, uint64(1), stats.Evictions)
+		}
+	}
+
+	// a was evicted, so the same call site gets a fresh copy with equal content.
+	require.NotSame(t, as[0].(*fundamental).stack, as[1].(*fundamental).stack)
+	require.Equal(t, *as[0].(*fundamental).stack, *as[1].(*fundamental).stack)
+	require.True(t, HasStack(b))
+	require.True(t, HasStack(c))
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// StackMetadata retrieves when and on which goroutine a stack trace was captured.
+// It is implemented by the errors carrying a stack trace, the values are only
+// recorded while EnableStackMetadata is in effect.
+// Generally you would want to use the GetStackMetadata function to get it.
+type StackMetadata interface {
+	// CreatedAt returns the creation time, it is zero if it was not recorded.
+	CreatedAt() time.Time
+	// GoroutineID returns the id of the creating goroutine, 0 if it was not recorded.
+	GoroutineID() int64
+}
+
+var stackMetadataEnabled atomic.Bool
+
+// EnableStackMetadata makes errors record their creation time and goroutine
+// id together with their stack trace. They are printed by %+v.
+func EnableStackMetadata() {
+	stackMetadataEnabled.Store(true)
+}
+
+// DisableStackMetadata stops recording creation time and goroutine id.
+func DisableStackMetadata() {
+	stackMetadataEnabled.Store(false)
+}
+
+// GetStackMetadata returns the first StackMetadata in the causer chain that
+// has recorded values, nil if there is none.
+func GetStackMetadata(origErr error) StackMetadata {
+	var found StackMetadata
+	WalkDeep(origErr, func(err error) bool {
+		if m, ok := err.(StackMetadata); ok && !m.CreatedAt().IsZero() {
+			found = m
+			return true
+		}
+		return false
+	})
+	return found
+}
+
+type stackMeta struct {
+	createdAt   time.Time
+	goroutineID int64
+}
+
+// captureStackMeta returns nil unless EnableStackMetadata is in effect.
+func captureStackMeta() *stackMeta {
+	if !stackMetadataEnabled.Load() {
+		return nil
+	}
+	return &stackMeta{
+		createdAt:   time.Now(),
+		goroutineID: currentGoroutineID(),
+	}
+}
+
+func (m *stackMeta) CreatedAt() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return m.createdAt
+}
+
+func (m *stackMeta) GoroutineID() int64 {
+	if m == nil {
+		return 0
+	}
+	return m.goroutineID
+}
+
+// Format writes the metadata as its own line for %+v, nothing if it was not recorded.
+func (m *stackMeta) Format(s fmt.State, verb rune) {
+	if m == nil || verb != 'v' || !s.Flag('+') {
+		return
+	}
+	io.WriteString(s, "\n")
+	fmt.Fprintf(s, stackMetaLayout, m.goroutineID, m.createdAt.Format(time.RFC3339Nano))
+}
+
+const stackMetaLayout = "(goroutine %d, created at %s)"
+
+// currentGoroutineID parses the id from the header of the goroutine trace,
+// which looks like "goroutine 18 [running]:".
+func currentGoroutineID() int64 {
+	var buf [32]byte
+	n := runtime.Stack(buf[:], false)
+	b := buf[:n]
+	const prefix = "goroutine "
+	if len(b) < len(prefix) || string(b[:len(prefix)]) != prefix {
+		return 0
+	}
+	var id int64
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + int64(c-'0')
+	}
+	return id
+}
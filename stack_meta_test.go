@@ -0,0 +1,62 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackMetadata(t *testing.T) {
+	require.Nil(t, GetStackMetadata(New("no metadata")))
+	require.NotContains(t, fmt.Sprintf("%+v", New("no metadata")), "goroutine")
+
+	EnableStackMetadata()
+	defer DisableStackMetadata()
+
+	before := time.Now()
+	idCh := make(chan int64, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		idCh <- currentGoroutineID()
+		errCh <- New("in goroutine")
+	}()
+	id, err := <-idCh, <-errCh
+	require.NotZero(t, id)
+	require.NotEqual(t, currentGoroutineID(), id)
+
+	m := GetStackMetadata(Annotate(err, "annotated"))
+	require.NotNil(t, m)
+	require.Equal(t, id, m.GoroutineID())
+	require.False(t, m.CreatedAt().Before(before))
+	require.False(t, m.CreatedAt().After(time.Now()))
+
+	lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+	require.Equal(t, "in goroutine", lines[0])
+	require.Regexp(t, regexp.MustCompile(fmt.Sprintf(`^\(goroutine %d, created at \S+\)$`, id)), lines[1])
+	require.Contains(t, lines[2], "TestStackMetadata")
+
+	wrapped := WithStack(io.EOF)
+	require.Equal(t, currentGoroutineID(), GetStackMetadata(wrapped).GoroutineID())
+	require.Contains(t, fmt.Sprintf("%+v", wrapped), fmt.Sprintf("EOF\n(goroutine %d, ", currentGoroutineID()))
+
+	// the metadata describes the stack, so it is cleared together with it.
+	require.Nil(t, GetStackMetadata(SuspendStack(wrapped)))
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// StackSampler decides whether an error generated by SampledGenByArgs
+// captures a stack trace. Implementations must be safe for concurrent use.
+type StackSampler interface {
+	Sample() bool
+}
+
+// StackSamplerFunc adapts an ordinary function to a StackSampler.
+type StackSamplerFunc func() bool
+
+// Sample implements StackSampler.
+func (f StackSamplerFunc) Sample() bool { return f() }
+
+// SampleOneIn returns a StackSampler capturing the stack of one error in every n.
+// The first error is always sampled. n <= 1 samples every error.
+func SampleOneIn(n int) StackSampler {
+	if n <= 1 {
+		return StackSamplerFunc(func() bool { return true })
+	}
+	var count atomic.Uint64
+	return StackSamplerFunc(func() bool {
+		return (count.Inc()-1)%uint64(n) == 0
+	})
+}
+
+// SampleByTokenBucket returns a StackSampler allowing at most perSecond stack
+// captures per second on average, with bursts up to burst captures.
+func SampleByTokenBucket(perSecond float64, burst int) StackSampler {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) Sample() bool {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// StackSampleStats counts the sampling decisions made for a sampler.
+type StackSampleStats struct {
+	Seen    uint64
+	Sampled uint64
+}
+
+// Rate returns the observed fraction of errors whose stack was captured.
+// It is 1 before any decision was made.
+func (s StackSampleStats) Rate() float64 {
+	if s.Seen == 0 {
+		return 1
+	}
+	return float64(s.Sampled) / float64(s.Seen)
+}
+
+// countingSampler wraps a registered StackSampler to expose its sample rate.
+type countingSampler struct {
+	sampler StackSampler
+	seen    atomic.Uint64
+	sampled atomic.Uint64
+}
+
+func (c *countingSampler) sample() bool {
+	c.seen.Inc()
+	if c.sampler.Sample() {
+		c.sampled.Inc()
+		return true
+	}
+	return false
+}
+
+func (c *countingSampler) stats() StackSampleStats {
+	return StackSampleStats{Seen: c.seen.Load(), Sampled: c.sampled.Load()}
+}
+
+var (
+	// stackSamplersMu serializes writers, readers load the copy-on-write map
+	// from stackSamplers without locking.
+	stackSamplersMu sync.Mutex
+	stackSamplers   atomic.Value
+	defaultSampler  atomic.Value
+)
+
+func init() {
+	stackSamplers.Store(map[RFCErrorCode]*countingSampler{})
+	defaultSampler.Store((*countingSampler)(nil))
+}
+
+// SetStackSampler sets the StackSampler used by SampledGenByArgs for errors
+// with the given RFC code. A nil sampler removes it, so the default applies again.
+func SetStackSampler(code RFCErrorCode, sampler StackSampler) {
+	stackSamplersMu.Lock()
+	defer stackSamplersMu.Unlock()
+	old := stackSamplers.Load().(map[RFCErrorCode]*countingSampler)
+	samplers := make(map[RFCErrorCode]*countingSampler, len(old)+1)
+	for k, v := range old {
+		samplers[k] = v
+	}
+	if sampler == nil {
+		delete(samplers, code)
+	} else {
+		samplers[code] = &countingSampler{sampler: sampler}
+	}
+	stackSamplers.Store(samplers)
+}
+
+// SetDefaultStackSampler sets the StackSampler used by SampledGenByArgs for
+// errors without a sampler of their own. A nil sampler captures every stack.
+func SetDefaultStackSampler(sampler StackSampler) {
+	if sampler == nil {
+		defaultSampler.Store((*countingSampler)(nil))
+		return
+	}
+	defaultSampler.Store(&countingSampler{sampler: sampler})
+}
+
+// GetStackSampleStats returns the sampling decisions made for errors with the
+// given RFC code. If the code has no sampler of its own, the statistics of the
+// default sampler are returned.
+func GetStackSampleStats(code RFCErrorCode) StackSampleStats {
+	if s := lookupStackSampler(code); s != nil {
+		return s.stats()
+	}
+	return StackSampleStats{}
+}
+
+func lookupStackSampler(code RFCErrorCode) *countingSampler {
+	if s, ok := stackSamplers.Load().(map[RFCErrorCode]*countingSampler)[code]; ok {
+		return s
+	}
+	return defaultSampler.Load().(*countingSampler)
+}
+
+// shouldSampleStack reports whether an error with the given code captures its stack.
+func shouldSampleStack(code RFCErrorCode) bool {
+	s := lookupStackSampler(code)
+	return s == nil || s.sample()
+}
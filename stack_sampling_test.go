@@ -0,0 +1,65 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampledGenByArgs(t *testing.T) {
+	errSampled := Normalize("sampled %d", RFCCodeText("Internal:Sampled"))
+	errOther := Normalize("other %d", RFCCodeText("Internal:Other"))
+
+	// without any sampler every stack is captured.
+	err := errSampled.SampledGenByArgs(1)
+	require.True(t, HasStack(err))
+	require.Equal(t, "[Internal:Sampled]sampled 1", err.Error())
+	require.Equal(t, StackSampleStats{}, GetStackSampleStats(errSampled.RFCCode()))
+
+	SetStackSampler(errSampled.RFCCode(), SampleOneIn(3))
+	defer SetStackSampler(errSampled.RFCCode(), nil)
+	var captured []bool
+	for i := 0; i < 6; i++ {
+		err := errSampled.SampledGenByArgs(i)
+		require.True(t, errSampled.Equal(err))
+		captured = append(captured, HasStack(err))
+	}
+	require.Equal(t, []bool{true, false, false, true, false, false}, captured)
+	stats := GetStackSampleStats(errSampled.RFCCode())
+	require.Equal(t, StackSampleStats{Seen: 6, Sampled: 2}, stats)
+	require.InDelta(t, 1.0/3, stats.Rate(), 1e-9)
+
+	// a stack can still be added to a sampled out error.
+	require.True(t, HasStack(errSampled.SampledGenByArgs(7)))
+	err = errSampled.SampledGenByArgs(8)
+	require.False(t, HasStack(err))
+	require.True(t, HasStack(Trace(err)))
+
+	SetDefaultStackSampler(StackSamplerFunc(func() bool { return false }))
+	defer SetDefaultStackSampler(nil)
+	require.False(t, HasStack(errOther.SampledGenByArgs(1)))
+	require.Equal(t, StackSampleStats{Seen: 1}, GetStackSampleStats(errOther.RFCCode()))
+	require.Equal(t, 0.0, GetStackSampleStats(errOther.RFCCode()).Rate())
+}
+
+func TestSampleByTokenBucket(t *testing.T) {
+	sampler := SampleByTokenBucket(0, 2)
+	require.True(t, sampler.Sample())
+	require.True(t, sampler.Sample())
+	require.False(t, sampler.Sample())
+
+	require.True(t, SampleOneIn(0).Sample())
+}
@@ -0,0 +1,307 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.uber.org/atomic"
+)
+
+// templateVerb is a printf verb of a message template.
+type templateVerb struct {
+	verb rune
+	// arg is the index of the arg formatted by the verb.
+	arg int
+	// simple is set if the verb has no flags, width, precision nor arg index.
+	simple bool
+}
+
+// parsedTemplate is a printf message template parsed by parseTemplate.
+type parsedTemplate struct {
+	// literals[i] is the text before verbs[i], with "%%" unescaped. The last
+	// one is the text after the last verb.
+	literals []string
+	verbs    []templateVerb
+	// starArgs are the indexes of the args giving a width or a precision with '*'.
+	starArgs []int
+	// argCount is the number of args the template takes.
+	argCount int
+	// problem describes why the template is malformed, if it is.
+	problem string
+}
+
+// parseTemplate parses a printf template the way fmt does.
+func parseTemplate(tmpl string) *parsedTemplate {
+	t := &parsedTemplate{}
+	var lit strings.Builder
+	next := 0
+	use := func(arg int) {
+		if arg+1 > t.argCount {
+			t.argCount = arg + 1
+		}
+	}
+	for i := 0; i < len(tmpl); {
+		if tmpl[i] != '%' {
+			lit.WriteByte(tmpl[i])
+			i++
+			continue
+		}
+		start := i
+		i++
+		if i < len(tmpl) && tmpl[i] == '%' {
+			lit.WriteByte('%')
+			i++
+			continue
+		}
+		simple := true
+	flags:
+		for ; i < len(tmpl); i++ {
+			switch c := tmpl[i]; {
+			case c == '[':
+				simple = false
+				end := strings.IndexByte(tmpl[i:], ']')
+				n, err := 0, error(nil)
+				if end > 0 {
+					n, err = strconv.Atoi(tmpl[i+1 : i+end])
+				}
+				if end < 0 || err != nil || n < 1 {
+					t.problem = fmt.Sprintf("bad arg index in %q", tmpl[start:])
+					break flags
+				}
+				next = n - 1
+				i += end
+			case c == '*':
+				simple = false
+				t.starArgs = append(t.starArgs, next)
+				use(next)
+				next++
+			case strings.IndexByte("+-# 0.123456789", c) >= 0:
+				simple = false
+			default:
+				break flags
+			}
+		}
+		if i >= len(tmpl) {
+			t.problem = fmt.Sprintf("missing verb at the end of %q", tmpl)
+			lit.WriteString(tmpl[start:])
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(tmpl[i:])
+		i += size
+		t.literals = append(t.literals, lit.String())
+		lit.Reset()
+		t.verbs = append(t.verbs, templateVerb{verb: verb, arg: next, simple: simple})
+		use(next)
+		next++
+	}
+	t.literals = append(t.literals, lit.String())
+	return t
+}
+
+// render formats args without fmt if every verb is a simple %s, %d or %v
+// taking the args in order, and every arg is a string or an integer matching
+// its verb. It reports false otherwise, and the template must be formatted by fmt.
+func (t *parsedTemplate) render(args []interface{}) (string, bool) {
+	if t.problem != "" || len(t.starArgs) > 0 || len(args) != len(t.verbs) {
+		return "", false
+	}
+	size := 0
+	for i, lit := range t.literals {
+		size += len(lit)
+		if i < len(args) {
+			if s, ok := args[i].(string); ok {
+				size += len(s)
+			} else {
+				size += 20
+			}
+		}
+	}
+	buf := make([]byte, 0, size)
+	for i, v := range t.verbs {
+		if !v.simple {
+			return "", false
+		}
+		buf = append(buf, t.literals[i]...)
+		switch arg := args[i].(type) {
+		case string:
+			if v.verb != 's' && v.verb != 'v' {
+				return "", false
+			}
+			buf = append(buf, arg...)
+		case int:
+			if v.verb != 'd' && v.verb != 'v' {
+				return "", false
+			}
+			buf = strconv.AppendInt(buf, int64(arg), 10)
+		case int64:
+			if v.verb != 'd' && v.verb != 'v' {
+				return "", false
+			}
+			buf = strconv.AppendInt(buf, arg, 10)
+		case uint64:
+			if v.verb != 'd' && v.verb != 'v' {
+				return "", false
+			}
+			buf = strconv.AppendUint(buf, arg, 10)
+		default:
+			return "", false
+		}
+	}
+	buf = append(buf, t.literals[len(t.literals)-1]...)
+	return string(buf), true
+}
+
+// checkArgs returns a description of the first mismatch between the template
+// and args: their number, or an arg whose kind can not be formatted by its verb.
+func (t *parsedTemplate) checkArgs(args []interface{}) string {
+	if t.problem != "" {
+		return t.problem
+	}
+	if len(args) != t.argCount {
+		return fmt.Sprintf("takes %d args, got %d", t.argCount, len(args))
+	}
+	for _, i := range t.starArgs {
+		if !isIntegerKind(args[i]) {
+			return fmt.Sprintf("takes an int as arg %d for '*', got %T", i, args[i])
+		}
+	}
+	for _, v := range t.verbs {
+		if !verbAccepts(v.verb, args[v.arg]) {
+			return fmt.Sprintf("can not format arg %d of type %T with %%%c", v.arg, args[v.arg], v.verb)
+		}
+	}
+	return ""
+}
+
+func isIntegerKind(arg interface{}) bool {
+	switch reflect.ValueOf(arg).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// verbAccepts reports whether fmt can format arg with verb. Only the clear
+// mismatches are reported: args implementing fmt.Formatter, and collections
+// formatted element by element, are always accepted.
+func verbAccepts(verb rune, arg interface{}) bool {
+	if _, ok := arg.(fmt.Formatter); ok || arg == nil {
+		return true
+	}
+	kind := reflect.ValueOf(arg).Kind()
+	isString := kind == reflect.String
+	isFloat := kind == reflect.Float32 || kind == reflect.Float64 || kind == reflect.Complex64 || kind == reflect.Complex128
+	isBool := kind == reflect.Bool
+	switch verb {
+	case 'v', 'T':
+		return true
+	case 's':
+		switch arg.(type) {
+		case error, fmt.Stringer:
+			return true
+		}
+		return !isIntegerKind(arg) && !isFloat && !isBool
+	case 'd', 'c', 'U', 'o', 'O':
+		return !isString && !isFloat && !isBool
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return !isString && !isIntegerKind(arg) && !isBool
+	case 't':
+		return isBool || kind == reflect.Slice || kind == reflect.Array
+	}
+	return true
+}
+
+// TemplateValidation tells how the message templates of *Error and their args
+// are validated.
+type TemplateValidation int32
+
+const (
+	// TemplateValidationOff validates nothing. It is the default.
+	TemplateValidationOff TemplateValidation = iota
+	// TemplateValidationPanic makes Normalize panic on malformed templates, and
+	// the Gen* methods on args not matching their template. It is meant for tests.
+	TemplateValidationPanic
+	// TemplateValidationHook reports the same problems to the hook set by
+	// SetTemplateValidationHook, e.g. to log them in production.
+	TemplateValidationHook
+)
+
+var (
+	templateValidation     atomic.Int32
+	templateValidationMu   sync.RWMutex
+	templateValidationHook func(error)
+)
+
+// SetTemplateValidation sets how the message templates of *Error are validated.
+// Normalize validates the syntax of the template, GenWithStackByArgs,
+// FastGenByArgs and SampledGenByArgs validate the number and kinds of their args
+// against it, as well as GenWithStack and FastGen against their format.
+func SetTemplateValidation(mode TemplateValidation) {
+	templateValidation.Store(int32(mode))
+}
+
+// SetTemplateValidationHook sets the function receiving the problems found in
+// TemplateValidationHook mode. It must be safe for concurrent use.
+func SetTemplateValidationHook(hook func(error)) {
+	templateValidationMu.Lock()
+	defer templateValidationMu.Unlock()
+	templateValidationHook = hook
+}
+
+func reportTemplateProblem(err error) {
+	if TemplateValidation(templateValidation.Load()) == TemplateValidationPanic {
+		panic(err)
+	}
+	templateValidationMu.RLock()
+	hook := templateValidationHook
+	templateValidationMu.RUnlock()
+	if hook != nil {
+		hook(err)
+	}
+}
+
+// validateTemplate validates the template of an *Error created by Normalize,
+// along with the other problem found in its definition, if any.
+func validateTemplate(e *Error, problem string) {
+	if TemplateValidation(templateValidation.Load()) == TemplateValidationOff {
+		return
+	}
+	if e.tmpl.problem != "" {
+		problem = e.tmpl.problem
+	}
+	if problem != "" {
+		reportTemplateProblem(Errorf("invalid message template of error %s: %s", e.RFCCode(), problem))
+	}
+}
+
+// validateArgs validates args against the template text of e, parsed as t if
+// it is not nil.
+func validateArgs(e *Error, text string, t *parsedTemplate, args []interface{}) {
+	if TemplateValidation(templateValidation.Load()) == TemplateValidationOff {
+		return
+	}
+	if t == nil {
+		t = parseTemplate(text)
+	}
+	if problem := t.checkArgs(args); problem != "" {
+		reportTemplateProblem(Errorf("invalid args for error %s: template %q %s", e.RFCCode(), text, problem))
+	}
+}
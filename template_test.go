@@ -0,0 +1,101 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tmpl := parseTemplate("key %s: 100%% of %[1]q, %-5d rows")
+	require.Equal(t, []string{"key ", ": 100% of ", ", ", " rows"}, tmpl.literals)
+	require.Equal(t, []templateVerb{{'s', 0, true}, {'q', 0, false}, {'d', 1, false}}, tmpl.verbs)
+	require.Equal(t, 2, tmpl.argCount)
+	require.Empty(t, tmpl.problem)
+
+	require.Equal(t, []int{0}, parseTemplate("%*d").starArgs)
+	require.NotEmpty(t, parseTemplate("100%").problem)
+	require.NotEmpty(t, parseTemplate("%[0]d").problem)
+	require.NotEmpty(t, parseTemplate("%[x]d").problem)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	cases := []struct {
+		tmpl string
+		args []interface{}
+		fast bool
+	}{
+		{"table %s has %d rows, %v", []interface{}{"t", 3, int64(-4)}, true},
+		{"%v%v", []interface{}{"a", uint64(5)}, true},
+		{"%s", []interface{}{1}, false},
+		{"%d", []interface{}{"1"}, false},
+		{"%5s", []interface{}{"a"}, false},
+		{"%s %s", []interface{}{"a"}, false},
+		{"%s", []interface{}{"a", "b"}, false},
+		{"%x", []interface{}{"a"}, false},
+		{"%v", []interface{}{io.EOF}, false},
+		{"%d", []interface{}{int32(1)}, false},
+	}
+	for _, c := range cases {
+		msg, ok := parseTemplate(c.tmpl).render(c.args)
+		require.Equal(t, c.fast, ok, c.tmpl)
+		if ok {
+			require.Equal(t, fmt.Sprintf(c.tmpl, c.args...), msg, c.tmpl)
+		}
+		e := Normalize(c.tmpl).FastGenByArgs(c.args...).(*Error)
+		require.Equal(t, fmt.Sprintf(c.tmpl, c.args...), e.GetMsg(), c.tmpl)
+	}
+}
+
+func TestTemplateValidation(t *testing.T) {
+	var problems []error
+	SetTemplateValidationHook(func(err error) { problems = append(problems, err) })
+	SetTemplateValidation(TemplateValidationHook)
+	defer func() {
+		SetTemplateValidation(TemplateValidationOff)
+		SetTemplateValidationHook(nil)
+	}()
+
+	errRows := Normalize("table %s has %d rows", RFCCodeText("test-tmpl:Rows"))
+	_ = errRows.GenWithStackByArgs("t", 1)
+	_ = errRows.FastGenByArgs("t", uint8(1))
+	_ = errRows.SampledGenByArgs(fmt.Stringer(nil), 1)
+	_ = errRows.GenWithStack("%s %.*f", "x", 2, 1.5)
+	require.Empty(t, problems)
+
+	_ = errRows.GenWithStackByArgs("t")
+	_ = errRows.FastGenByArgs("t", "1")
+	_ = errRows.SampledGenByArgs(1, 1)
+	_ = errRows.FastGen("%t", 1)
+	_ = errRows.GenWithStack("%*d", "2", 1)
+	_ = Normalize("100%", RFCCodeText("test-tmpl:Percent"))
+	require.Len(t, problems, 6)
+	require.Contains(t, problems[0].Error(), `template "table %s has %d rows" takes 2 args, got 1`)
+	require.Contains(t, problems[1].Error(), "can not format arg 1 of type string with %d")
+	require.Contains(t, problems[2].Error(), "can not format arg 0 of type int with %s")
+	require.Contains(t, problems[3].Error(), "with %t")
+	require.Contains(t, problems[4].Error(), "takes an int as arg 0 for '*'")
+	require.Contains(t, problems[5].Error(), "invalid message template of error test-tmpl:Percent")
+
+	SetTemplateValidation(TemplateValidationPanic)
+	require.Panics(t, func() { _ = errRows.FastGenByArgs() })
+	require.NotPanics(t, func() { _ = errRows.FastGenByArgs("t", 1) })
+
+	SetTemplateValidation(TemplateValidationOff)
+	require.NotPanics(t, func() { _ = errRows.FastGenByArgs() })
+}
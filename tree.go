@@ -0,0 +1,156 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TreeOption customizes the output of Tree.
+type TreeOption func(*treeOptions)
+
+type treeOptions struct {
+	color bool
+}
+
+// TreeANSIColor highlights the parts of every node with ANSI escape codes, for terminals.
+func TreeANSIColor() TreeOption {
+	return func(o *treeOptions) {
+		o.color = true
+	}
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiType   = "\x1b[36m"
+	ansiCode   = "\x1b[33m"
+	ansiMsg    = "\x1b[1m"
+	ansiFaint  = "\x1b[2m"
+	treeBranch = "├── "
+	treeLast   = "└── "
+	treePipe   = "│   "
+	treeSpace  = "    "
+)
+
+// Tree renders the whole graph of err, one node per line, with the wrapped error
+// of a node and the members of a Join or ErrorGroup drawn as its children.
+// Every node shows its type, the RFC code of an *Error, its own message as
+// returned by GetSelfMsg and where it was created, if known.
+//
+// The same output is produced by formatting the errors of this package with %#+v.
+func Tree(err error, opts ...TreeOption) string {
+	if err == nil {
+		return ""
+	}
+	var o treeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var b strings.Builder
+	writeTreeNode(&b, err, "", "", &o)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeTreeNode(b *strings.Builder, err error, first, rest string, o *treeOptions) {
+	b.WriteString(first)
+	writeTreeLabel(b, err, o)
+	b.WriteByte('\n')
+
+	children := treeChildren(err)
+	for i, child := range children {
+		if i == len(children)-1 {
+			writeTreeNode(b, child, rest+treeLast, rest+treeSpace, o)
+		} else {
+			writeTreeNode(b, child, rest+treeBranch, rest+treePipe, o)
+		}
+	}
+}
+
+func treeChildren(err error) []error {
+	var children []error
+	if cause := DirectCause(err); cause != nil {
+		children = append(children, cause)
+	}
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		children = append(children, x.Unwrap()...)
+	case ErrorGroup:
+		children = append(children, x.Errors()...)
+	}
+	return children
+}
+
+func writeTreeLabel(b *strings.Builder, err error, o *treeOptions) {
+	paint := func(color, s string) {
+		if o.color {
+			b.WriteString(color)
+			b.WriteString(s)
+			b.WriteString(ansiReset)
+			return
+		}
+		b.WriteString(s)
+	}
+
+	paint(ansiType, fmt.Sprintf("%T", err))
+	if e, ok := err.(*Error); ok {
+		b.WriteByte(' ')
+		paint(ansiCode, "["+string(e.RFCCode())+"]")
+	}
+	if msg := treeSelfMsg(err); msg != "" {
+		b.WriteByte(' ')
+		paint(ansiMsg, strconv.Quote(msg))
+	}
+	if loc := treeLocation(err); loc != "" {
+		b.WriteByte(' ')
+		paint(ansiFaint, "at "+loc)
+	}
+}
+
+// treeSelfMsg returns the message of err without the one of its children.
+func treeSelfMsg(err error) string {
+	if m, ok := err.(messenger); ok {
+		return m.GetSelfMsg()
+	}
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return ""
+	}
+	return err.Error()
+}
+
+// treeLocation returns the short location of the innermost frame of the stack
+// trace held by err itself, or the location an *Error was generated at.
+func treeLocation(err error) string {
+	switch x := err.(type) {
+	case *Error:
+		if file, line := x.Location(); file != "" {
+			return path.Base(file) + ":" + strconv.Itoa(line)
+		}
+	case *fundamental:
+		return stackLocation(x.stack)
+	case *withStack:
+		return stackLocation(x.stack)
+	}
+	return ""
+}
+
+func stackLocation(s *stack) string {
+	if s == nil || s.Empty() {
+		return ""
+	}
+	f := Frame((*s)[0])
+	return funcname(f.Func()) + " " + path.Base(f.file()) + ":" + strconv.Itoa(f.line())
+}
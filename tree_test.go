@@ -0,0 +1,56 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTree(t *testing.T) {
+	require.Equal(t, "", Tree(nil))
+
+	errTest := Normalize("named error: %s", RFCCodeText("Internal:Test"))
+	err := Annotate(Join(
+		errTest.Wrap(New("cause")).GenWithStackByArgs("x"),
+		WithMessage(io.EOF, "reading"),
+	), "failed")
+
+	want := []string{
+		`^\*errors\.withStack at TestTree tree_test\.go:\d+$`,
+		`^└── \*errors\.withMessage "failed"$`,
+		`^    └── \*errors\.joinError$`,
+		`^        ├── \*errors\.Error \[Internal:Test\] "named error: x" at tree_test\.go:\d+$`,
+		`^        │   └── \*errors\.fundamental "cause" at TestTree tree_test\.go:\d+$`,
+		`^        └── \*errors\.withMessage "reading"$`,
+		`^            └── \*errors\.errorString "EOF"$`,
+	}
+	for _, got := range []string{Tree(err), fmt.Sprintf("%#+v", err)} {
+		lines := strings.Split(got, "\n")
+		require.Len(t, lines, len(want), got)
+		for i := range want {
+			require.Regexp(t, regexp.MustCompile(want[i]), lines[i])
+		}
+	}
+	require.Equal(t, Tree(errTest), fmt.Sprintf("%#+v", errTest))
+	require.Equal(t, `*errors.Error [Internal:Test] "named error: %s"`, Tree(errTest))
+
+	colored := Tree(errTest, TreeANSIColor())
+	require.Equal(t, "\x1b[36m*errors.Error\x1b[0m \x1b[33m[Internal:Test]\x1b[0m \x1b[1m\"named error: %s\"\x1b[0m", colored)
+}